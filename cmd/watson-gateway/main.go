@@ -0,0 +1,137 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Command watson-gateway runs the gateway implemented by package server, holding the Watson credentials
+(taken from $VCAP_SERVICES) on behalf of internal callers.
+
+Callers authenticate with an API key, sent as "Authorization: Bearer <key>". Keys are read from the
+file given with -keys, one "<key> <tenant>" pair per line.
+
+Usage:
+
+	watson-gateway -keys keys.txt [-listen :8080] [-services tone,translate] [-rate 600] [-cache 1000]
+*/
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/server"
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+type apiKey struct {
+	key    []byte
+	tenant string
+}
+
+func readKeys(path string) ([]apiKey, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var keys []apiKey
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, errors.New("malformed key line: expected \"<key> <tenant>\"")
+		}
+		keys = append(keys, apiKey{key: []byte(fields[0]), tenant: fields[1]})
+	}
+	return keys, scanner.Err()
+}
+
+// authenticator checks the bearer token of a request against keys, in constant time
+func authenticator(keys []apiKey) server.Authenticator {
+	return func(r *http.Request) (string, error) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return "", errors.New("missing bearer token")
+		}
+		token := []byte(strings.TrimPrefix(auth, "Bearer "))
+		tenant := ""
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare(k.key, token) == 1 {
+				tenant = k.tenant
+			}
+		}
+		if len(tenant) == 0 {
+			return "", errors.New("invalid bearer token")
+		}
+		return tenant, nil
+	}
+}
+
+func main() {
+	listen := flag.String("listen", ":8080", "`address` to listen on")
+	keysFile := flag.String("keys", "", "`file` with \"<key> <tenant>\" lines")
+	services := flag.String("services", "tone,translate,classify,synthesize,recognize", "comma separated list of services to expose")
+	rate := flag.Int("rate", 600, "calls allowed per tenant per minute; 0 for unlimited")
+	cacheSize := flag.Int("cache", 1000, "number of cached replies; 0 disables caching")
+	optOut := flag.Bool("opt-out", true, "opt out of Watson request logging")
+	flag.Parse()
+
+	if len(*keysFile) == 0 {
+		log.Fatal("watson-gateway: -keys is required")
+	}
+	keys, err := readKeys(*keysFile)
+	if err != nil {
+		log.Fatal("watson-gateway: ", err)
+	}
+
+	cfg := server.Config{
+		Authenticate: authenticator(keys),
+		CacheSize:    *cacheSize,
+	}
+	if *rate > 0 {
+		cfg.Quota = server.NewRateQuota(*rate, time.Minute)
+	}
+	for _, name := range strings.Split(*services, ",") {
+		wcfg := &watson.Config{LearningOptOut: *optOut}
+		switch strings.TrimSpace(name) {
+		case "tone":
+			cfg.Tone = wcfg
+		case "translate":
+			cfg.Translation = wcfg
+		case "classify":
+			cfg.Classifier = wcfg
+		case "synthesize":
+			cfg.TextToSpeech = wcfg
+		case "recognize":
+			cfg.SpeechToText = wcfg
+		default:
+			log.Fatalf("watson-gateway: unknown service %q", name)
+		}
+	}
+	s, err := server.New(cfg)
+	if err != nil {
+		log.Fatal("watson-gateway: ", err)
+	}
+	log.Fatal(http.ListenAndServe(*listen, s))
+}
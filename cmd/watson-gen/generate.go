@@ -0,0 +1,420 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+const licenseHeader = `//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+`
+
+// methods lists the HTTP methods, in the order their operations are generated for a given path
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+type generator struct {
+	spec    *Spec
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// generate returns the formatted Go source of package pkg implementing spec. The types in the spec
+// definitions become structs, and its operations become methods on the package's Client type, which
+// must provide 'version string' and 'watsonClient *watson.Client' fields.
+func generate(spec *Spec, pkg string, source string) ([]byte, error) {
+	g := &generator{spec: spec, imports: make(map[string]bool)}
+	for _, name := range spec.Definitions.Keys {
+		err := g.typeDecl(name, spec.Definitions.Values[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range spec.Paths.Keys {
+		for _, method := range methods {
+			op, ok := spec.Paths.Values[path][method]
+			if !ok || op.Skip {
+				continue
+			}
+			err := g.method(strings.ToUpper(method), path, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %s", method, path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(licenseHeader)
+	fmt.Fprintf(&out, "\n// Code generated by watson-gen from %s. DO NOT EDIT.\n\npackage %s\n\n", source, pkg)
+	if len(g.imports) > 0 {
+		var imports []string
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		out.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// comment writes text as a line comment, with the given indentation
+func (g *generator) comment(indent string, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		g.printf("%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func (g *generator) typeDecl(name string, s Schema) error {
+	if s.Type != "object" || len(s.Properties.Keys) == 0 {
+		return errors.New("definition " + name + ": only objects with properties are supported")
+	}
+	required := make(map[string]bool)
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	if len(s.Description) > 0 {
+		g.comment("", s.Description)
+	}
+	g.printf("type %s struct {\n", name)
+	for _, prop := range s.Properties.Keys {
+		p := s.Properties.Values[prop]
+		if len(p.Description) > 0 {
+			g.comment("\t", p.Description)
+		}
+		field := p.GoName
+		if len(field) == 0 {
+			field = goName(prop)
+		}
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:\"%s\"`\n", field, goType(&p), tag)
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+func (g *generator) method(method string, path string, op Operation) error {
+	if len(op.OperationId) == 0 {
+		return errors.New("missing operationId")
+	}
+	var args []string
+	var pre bytes.Buffer
+	errDeclared := false
+
+	// response type, and the zero value returned along with errors
+	ret, zero := "", ""
+	for _, code := range []string{"200", "201"} {
+		if r, ok := op.Responses[code]; ok && r.Schema != nil {
+			ret = goType(r.Schema)
+			zero = ret + "{}"
+			if strings.HasPrefix(ret, "[]") || strings.HasPrefix(ret, "map[") {
+				zero = "nil"
+			}
+			break
+		}
+	}
+	errReturn := "return err"
+	if len(ret) > 0 {
+		errReturn = "return " + zero + ", err"
+	}
+
+	// path parameters are taken from the path template, in order; parts holds the path as a list of
+	// Go expressions, where literals are kept unquoted and marked by a leading '"'
+	parts := []string{"c.version"}
+	rest := path
+	for {
+		i := strings.Index(rest, "{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(rest, "}")
+		if j < i {
+			return errors.New("malformed path template")
+		}
+		param := rest[i+1 : j]
+		args = append(args, param+" string")
+		parts = append(parts, `"`+rest[:i], param)
+		rest = rest[j+1:]
+	}
+	if len(rest) > 0 {
+		parts = append(parts, `"`+rest)
+	}
+
+	// body
+	bodyExpr := "nil"
+	for _, p := range op.Parameters {
+		if p.In != "body" {
+			continue
+		}
+		switch {
+		case p.Schema == nil:
+			return errors.New("body parameter " + p.Name + " has no schema")
+		case p.Flatten:
+			required := make(map[string]bool)
+			for _, r := range p.Schema.Required {
+				required[r] = true
+			}
+			fmt.Fprintf(&pre, "\treq := map[string]interface{}{\n")
+			for _, prop := range p.Schema.Properties.Keys {
+				ps := p.Schema.Properties.Values[prop]
+				if required[prop] {
+					args = append(args, prop+" "+goType(&ps))
+					fmt.Fprintf(&pre, "\t\t%q: %s,\n", prop, prop)
+				} else {
+					args = append(args, prop+" "+optionalType(&ps))
+				}
+			}
+			fmt.Fprintf(&pre, "\t}\n")
+			for _, prop := range p.Schema.Properties.Keys {
+				ps := p.Schema.Properties.Values[prop]
+				if !required[prop] {
+					fmt.Fprintf(&pre, "\tif %s {\n\t\treq[%q] = %s\n\t}\n", isSet(prop, &ps), prop, prop)
+				}
+			}
+			fmt.Fprintf(&pre, "\n\treq_json, err := json.Marshal(req)\n\tif err != nil {\n\t\t%s\n\t}\n\n", errReturn)
+			g.imports["bytes"], g.imports["encoding/json"] = true, true
+			bodyExpr = "bytes.NewReader(req_json)"
+			errDeclared = true
+		case p.Schema.Type == "string":
+			args = append(args, p.Name+" string")
+			g.imports["strings"] = true
+			bodyExpr = "strings.NewReader(" + p.Name + ")"
+		default:
+			args = append(args, p.Name+" "+goType(p.Schema))
+			fmt.Fprintf(&pre, "\t%s_json, err := json.Marshal(%s)\n\tif err != nil {\n\t\t%s\n\t}\n\n", p.Name, p.Name, errReturn)
+			g.imports["bytes"], g.imports["encoding/json"] = true, true
+			bodyExpr = "bytes.NewReader(" + p.Name + "_json)"
+			errDeclared = true
+		}
+	}
+
+	// query parameters
+	var query []Parameter
+	for _, p := range op.Parameters {
+		if p.In == "query" {
+			query = append(query, p)
+		}
+	}
+	if len(query) > 0 || op.Options {
+		g.imports["net/url"] = true
+		fmt.Fprintf(&pre, "\tq := url.Values{}\n")
+		if op.Options {
+			g.imports["fmt"] = true
+			fmt.Fprintf(&pre, "\tfor k, v := range options {\n\t\tq.Set(k, fmt.Sprintf(\"%%v\", v))\n\t}\n")
+		}
+		for _, p := range query {
+			if len(p.Value) > 0 {
+				fmt.Fprintf(&pre, "\tq.Set(%q, %s)\n", p.Name, p.Value)
+				continue
+			}
+			ps := Schema{Type: p.Type}
+			if len(ps.Type) == 0 {
+				ps.Type = "string"
+			}
+			value, err := formatQuery(p.Name, &ps)
+			if err != nil {
+				return errors.New("query parameter " + p.Name + ": " + err.Error())
+			}
+			if value != p.Name {
+				g.imports["strconv"] = true
+			}
+			args = append(args, p.Name+" "+goType(&ps))
+			if p.Required {
+				fmt.Fprintf(&pre, "\tq.Set(%q, %s)\n", p.Name, value)
+			} else {
+				fmt.Fprintf(&pre, "\tif %s {\n\t\tq.Set(%q, %s)\n\t}\n", isSet(p.Name, &ps), p.Name, value)
+			}
+		}
+		parts = append(parts, `"?`, "q.Encode()")
+	}
+	if op.Options {
+		args = append(args, "options map[string]interface{}")
+	}
+
+	headersExpr := "nil"
+	if bodyExpr != "nil" {
+		g.imports["net/http"] = true
+		if pre.Len() > 0 {
+			pre.WriteString("\n")
+		}
+		fmt.Fprintf(&pre, "\theaders := make(http.Header)\n")
+		fmt.Fprintf(&pre, "\theaders.Set(\"Content-Type\", %q)\n", first(op.Consumes, "application/json"))
+		fmt.Fprintf(&pre, "\theaders.Set(\"Accept\", %q)\n", first(op.Produces, "application/json"))
+		headersExpr = "headers"
+	}
+
+	g.comment("", "Calls '"+method+" /"+g.spec.Info.Version+path+"' to "+op.Summary)
+	if len(op.Description) > 0 {
+		g.comment("", op.Description)
+	}
+	results := "error"
+	if len(ret) > 0 {
+		results = "(" + ret + ", error)"
+	}
+	g.printf("func (c Client) %s(%s) %s {\n", op.OperationId, strings.Join(args, ", "), results)
+	g.buf.Write(pre.Bytes())
+	call := fmt.Sprintf("c.watsonClient.MakeRequest(%q, %s, %s, %s)", method, joinPath(parts), bodyExpr, headersExpr)
+	if len(ret) == 0 {
+		assign := ":="
+		if errDeclared {
+			assign = "="
+		}
+		g.printf("\t_, err %s %s\n\treturn err\n}\n\n", assign, call)
+		return nil
+	}
+	g.imports["encoding/json"] = true
+	g.printf("\tbody, err := %s\n\tif err != nil {\n\t\t%s\n\t}\n", call, errReturn)
+	g.printf("\tvar result %s\n\terr = json.Unmarshal(body, &result)\n\treturn result, err\n}\n\n", ret)
+	return nil
+}
+
+// joinPath concatenates path parts into a single Go expression, merging adjacent literals
+func joinPath(parts []string) string {
+	var exprs []string
+	literal := ""
+	for _, p := range parts {
+		if strings.HasPrefix(p, `"`) {
+			literal += p[1:]
+			continue
+		}
+		if len(literal) > 0 {
+			exprs = append(exprs, fmt.Sprintf("%q", literal))
+			literal = ""
+		}
+		exprs = append(exprs, p)
+	}
+	if len(literal) > 0 {
+		exprs = append(exprs, fmt.Sprintf("%q", literal))
+	}
+	return strings.Join(exprs, "+")
+}
+
+func first(values []string, def string) string {
+	if len(values) > 0 {
+		return values[0]
+	}
+	return def
+}
+
+// optionalType returns the Go type of an optional argument of schema s. Structs are passed by pointer, so
+// that nil can stand for an absent value.
+func optionalType(s *Schema) string {
+	t := goType(s)
+	if len(s.Ref) > 0 && len(s.GoType) == 0 {
+		return "*" + t
+	}
+	return t
+}
+
+// isSet returns a Go condition telling whether the optional argument name, of schema s, holds a value to send,
+// i.e. it is not the zero value of its type
+func isSet(name string, s *Schema) string {
+	t := optionalType(s)
+	switch {
+	case t == "string" || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map["):
+		return "len(" + name + ") > 0"
+	case t == "bool":
+		return name
+	case t == "int" || t == "int64" || t == "float64":
+		return name + " != 0"
+	}
+	return name + " != nil"
+}
+
+// formatQuery returns a Go expression converting the argument name, of scalar schema s, to a query value
+func formatQuery(name string, s *Schema) (string, error) {
+	switch goType(s) {
+	case "string":
+		return name, nil
+	case "int":
+		return "strconv.Itoa(" + name + ")", nil
+	case "float64":
+		return "strconv.FormatFloat(" + name + ", 'f', -1, 64)", nil
+	case "bool":
+		return "strconv.FormatBool(" + name + ")", nil
+	}
+	return "", errors.New("unsupported type " + s.Type)
+}
+
+// goName converts a JSON property name such as "base_model_id" to a Go field name ("BaseModelId")
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	for i, p := range parts {
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func goType(s *Schema) string {
+	if len(s.GoType) > 0 {
+		return s.GoType
+	}
+	if len(s.Ref) > 0 {
+		return strings.TrimPrefix(s.Ref, "#/definitions/")
+	}
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer":
+		if s.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		if s.Items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + goType(s.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedUpToDate checks that every generated file in the service packages matches its API definitions.
+func TestGeneratedUpToDate(t *testing.T) {
+	specs, err := filepath.Glob("../../watson/*/swagger.json")
+	if err != nil || len(specs) == 0 {
+		t.Errorf("no API definitions found: %#v\n", err)
+		return
+	}
+	for _, spec := range specs {
+		dir := filepath.Dir(spec)
+		src, err := generateFile(spec, filepath.Base(dir))
+		if err != nil {
+			t.Errorf("generateFile(%s) failed %s\n", spec, err)
+			continue
+		}
+		checkedIn, err := ioutil.ReadFile(filepath.Join(dir, "api_gen.go"))
+		if err != nil {
+			t.Errorf("reading generated file for %s failed %s\n", spec, err)
+			continue
+		}
+		if !bytes.Equal(src, checkedIn) {
+			t.Errorf("%s is out of date; run 'go generate' in %s\n", filepath.Join(dir, "api_gen.go"), dir)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"base_model_id": "BaseModelId",
+		"url":           "Url",
+		"content-type":  "ContentType",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %s, wanted %s\n", in, got, want)
+		}
+	}
+}
+
+func TestOptionalArguments(t *testing.T) {
+	spec := `{
+		"swagger": "2.0",
+		"info": {"title": "test", "version": "v1"},
+		"paths": {
+			"/things": {
+				"post": {
+					"operationId": "CreateThing",
+					"parameters": [
+						{"name": "body", "in": "body", "x-go-flatten": true, "schema": {
+							"type": "object",
+							"required": ["name"],
+							"properties": {
+								"name": {"type": "string"},
+								"size": {"type": "integer"},
+								"ratio": {"type": "number"},
+								"public": {"type": "boolean"},
+								"tags": {"type": "array", "items": {"type": "string"}},
+								"owner": {"$ref": "#/definitions/Owner"}
+							}
+						}},
+						{"name": "limit", "in": "query", "type": "integer"},
+						{"name": "verbose", "in": "query", "type": "boolean", "required": true},
+						{"name": "text", "in": "query"}
+					]
+				}
+			}
+		},
+		"definitions": {
+			"Owner": {"type": "object", "properties": {"id": {"type": "string"}}}
+		}
+	}`
+	var s Spec
+	if err := json.Unmarshal([]byte(spec), &s); err != nil {
+		t.Errorf("json.Unmarshal() failed %#v\n", err)
+		return
+	}
+	src, err := generate(&s, "test", "swagger.json")
+	if err != nil {
+		t.Errorf("generate() failed %s\n", err)
+		return
+	}
+	for _, want := range []string{
+		"func (c Client) CreateThing(name string, size int, ratio float64, public bool, tags []string, owner *Owner, limit int, verbose bool, text string) error {",
+		"if size != 0 {",
+		"if ratio != 0 {",
+		"if public {",
+		"if len(tags) > 0 {",
+		"if owner != nil {",
+		"if limit != 0 {\n\t\tq.Set(\"limit\", strconv.Itoa(limit))",
+		"q.Set(\"verbose\", strconv.FormatBool(verbose))",
+		"if len(text) > 0 {",
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("generated source missing %q:\n%s\n", want, src)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Command watson-gen generates service client code from Swagger 2.0 API definitions. It is meant to be run
+through 'go generate' from a service package directory, e.g.:
+
+	//go:generate go run ../../cmd/watson-gen -package tone_analyzer -o api_gen.go swagger.json
+
+Every object in the definitions becomes a struct; properties not listed as required are tagged
+omitempty. Every operation becomes a method on the package's Client type, named after its
+operationId, with arguments taken from path parameters (in order), the body and query parameters.
+The generated methods issue requests through watson.Client.MakeRequest and unmarshal the 200 (or 201)
+response schema.
+
+A few vendor extensions adjust the output:
+
+	x-go-name     (property)  Go field name, overriding the one derived from the JSON name
+	x-go-type     (schema)    Go type, overriding the one derived from the schema
+	x-go-options  (operation) add a trailing options map, sent as query parameters
+	x-go-skip     (operation) do not generate the operation, as it is implemented by hand
+	x-go-value    (parameter) Go expression used as the parameter value, instead of an argument
+	x-go-flatten  (parameter) pass the properties of an object body as individual arguments
+
+Hand-written code (NewClient, multipart uploads, streaming, helpers) lives alongside the generated
+file in the same package.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	pkg := flag.String("package", "", "name of the generated package")
+	out := flag.String("o", "api_gen.go", "output `file`")
+	flag.Parse()
+	if flag.NArg() != 1 || len(*pkg) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: watson-gen -package name [-o file] swagger.json")
+		os.Exit(2)
+	}
+
+	src, err := generateFile(flag.Arg(0), *pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watson-gen: %s\n", err)
+		os.Exit(1)
+	}
+	err = ioutil.WriteFile(*out, src, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watson-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// generateFile reads the definitions in file and returns the generated source for package pkg
+func generateFile(file string, pkg string) ([]byte, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	err = json.Unmarshal(b, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	if spec.Swagger != "2.0" {
+		return nil, fmt.Errorf("%s: unsupported swagger version %q", file, spec.Swagger)
+	}
+	return generate(&spec, pkg, filepath.Base(file))
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Spec is the subset of a Swagger 2.0 document understood by the generator.
+type Spec struct {
+	Swagger     string  `json:"swagger"`
+	Info        Info    `json:"info"`
+	Paths       Paths   `json:"paths"`
+	Definitions Schemas `json:"definitions"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Paths maps paths to their operations, keyed by lower case HTTP method. Keys holds the paths in the
+// order of the definitions file.
+type Paths struct {
+	Keys   []string
+	Values map[string]map[string]Operation
+}
+
+func (p *Paths) UnmarshalJSON(b []byte) error {
+	err := json.Unmarshal(b, &p.Values)
+	if err != nil {
+		return err
+	}
+	p.Keys, err = objectKeys(b)
+	return err
+}
+
+type Operation struct {
+	OperationId string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description"`
+	Consumes    []string            `json:"consumes"`
+	Produces    []string            `json:"produces"`
+	Parameters  []Parameter         `json:"parameters"`
+	Responses   map[string]Response `json:"responses"`
+	// Options adds a trailing 'options map[string]interface{}' argument, whose entries are sent as query parameters
+	Options bool `json:"x-go-options"`
+	// Skip excludes the operation from generation, typically because it is implemented by hand
+	Skip bool `json:"x-go-skip"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Type        string  `json:"type"`
+	Schema      *Schema `json:"schema"`
+	// Value, if set, is a Go expression sent as the parameter value instead of taking an argument
+	Value string `json:"x-go-value"`
+	// Flatten turns the properties of an object body into individual arguments
+	Flatten bool `json:"x-go-flatten"`
+}
+
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string   `json:"$ref"`
+	Type                 string   `json:"type"`
+	Format               string   `json:"format"`
+	Description          string   `json:"description"`
+	Required             []string `json:"required"`
+	Properties           Schemas  `json:"properties"`
+	Items                *Schema  `json:"items"`
+	AdditionalProperties *Schema  `json:"additionalProperties"`
+	// GoName overrides the Go name derived for a property
+	GoName string `json:"x-go-name"`
+	// GoType overrides the Go type derived for a schema
+	GoType string `json:"x-go-type"`
+}
+
+// Schemas maps names to schemas. Keys holds the names in the order of the definitions file, so that
+// generated code follows it.
+type Schemas struct {
+	Keys   []string
+	Values map[string]Schema
+}
+
+func (s *Schemas) UnmarshalJSON(b []byte) error {
+	err := json.Unmarshal(b, &s.Values)
+	if err != nil {
+		return err
+	}
+	s.Keys, err = objectKeys(b)
+	return err
+}
+
+// objectKeys returns the keys of the JSON object b, in order.
+func objectKeys(b []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, errors.New("expected JSON object")
+	}
+	var keys []string
+	for dec.More() {
+		t, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
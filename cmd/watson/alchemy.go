@@ -0,0 +1,216 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/alchemy/alchemy_data_news"
+	"github.com/liviosoares/go-watson-sdk/watson/alchemy/alchemy_language"
+	"github.com/liviosoares/go-watson-sdk/watson/alchemy/alchemy_vision"
+)
+
+const alchemyUsage = "usage: watson alchemy sentiment | emotion | taxonomy | concepts | entities | keywords | text | title | language [text | url] | image-keywords | faces <url> | news [-start s] [-end e] [-return fields] [param=value...]"
+
+// runAlchemy implements the 'watson alchemy' family of subcommands, covering AlchemyLanguage, AlchemyVision
+// and AlchemyData News
+func runAlchemy(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(alchemyUsage)
+	}
+	switch args[0] {
+	case "image-keywords", "faces":
+		return runAlchemyVision(cfg, out, args)
+	case "news":
+		return runAlchemyNews(cfg, out, args[1:])
+	}
+
+	client, err := alchemy_language.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	// the service tells urls, html and plain text apart, so arguments are passed along as they are
+	var data []byte
+	if len(args) > 1 {
+		data = []byte(strings.Join(args[1:], " "))
+	} else {
+		data, err = readInput(nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "sentiment":
+		response, err := client.GetSentiment(data, nil)
+		if err != nil {
+			return err
+		}
+		rows := [][]string{{response.DocSentiment.Type, formatScore(response.DocSentiment.Score)}}
+		return out.print(response, []string{"SENTIMENT", "SCORE"}, rows)
+
+	case "emotion":
+		response, err := client.GetEmotion(data, nil)
+		if err != nil {
+			return err
+		}
+		e := response.DocEmotions
+		rows := [][]string{
+			{"anger", formatScore(e.Anger)},
+			{"disgust", formatScore(e.Disgust)},
+			{"fear", formatScore(e.Fear)},
+			{"joy", formatScore(e.Joy)},
+			{"sadness", formatScore(e.Sadness)},
+		}
+		return out.print(response, []string{"EMOTION", "SCORE"}, rows)
+
+	case "taxonomy":
+		response, err := client.GetTaxonomy(data, nil)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, t := range response.Taxonomy {
+			rows = append(rows, []string{t.Label, formatScore(t.Score)})
+		}
+		return out.print(response, []string{"LABEL", "SCORE"}, rows)
+
+	case "concepts":
+		response, err := client.GetConcepts(data, nil)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range response.Concepts {
+			rows = append(rows, []string{c.Text, formatScore(c.Relevance)})
+		}
+		return out.print(response, []string{"CONCEPT", "RELEVANCE"}, rows)
+
+	case "entities":
+		response, err := client.GetNamedEntities(data, nil)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, e := range response.Entities {
+			rows = append(rows, []string{e.Type, e.Text, strconv.Itoa(e.Count), formatScore(e.Relevance)})
+		}
+		return out.print(response, []string{"TYPE", "ENTITY", "COUNT", "RELEVANCE"}, rows)
+
+	case "keywords":
+		response, err := client.GetKeywords(data, nil)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, k := range response.Keywords {
+			rows = append(rows, []string{k.Text, formatScore(k.Relevance)})
+		}
+		return out.print(response, []string{"KEYWORD", "RELEVANCE"}, rows)
+
+	case "text":
+		response, err := client.GetText(data, nil)
+		if err != nil {
+			return err
+		}
+		return out.print(response, nil, [][]string{{response.Text}})
+
+	case "title":
+		response, err := client.GetTitle(data, nil)
+		if err != nil {
+			return err
+		}
+		return out.print(response, nil, [][]string{{response.Text}})
+
+	case "language":
+		response, err := client.GetLanguage(data, nil)
+		if err != nil {
+			return err
+		}
+		rows := [][]string{{response.Language, response.ISO6391, response.NativeSpeakers}}
+		return out.print(response, []string{"LANGUAGE", "ISO-639-1", "SPEAKERS"}, rows)
+	}
+	return errors.New(alchemyUsage)
+}
+
+func runAlchemyVision(cfg watson.Config, out output, args []string) error {
+	if len(args) != 2 {
+		return errors.New(alchemyUsage)
+	}
+	client, err := alchemy_vision.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	if args[0] == "image-keywords" {
+		response, err := client.GetImageKeywords([]byte(args[1]), nil)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, k := range response.ImageKeywords {
+			rows = append(rows, []string{k.Text, formatScore(k.Score)})
+		}
+		return out.print(response, []string{"KEYWORD", "SCORE"}, rows)
+	}
+	response, err := client.GetImageFaceTags([]byte(args[1]), nil)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, f := range response.ImageFaces {
+		rows = append(rows, []string{
+			strconv.Itoa(f.PositionX) + "," + strconv.Itoa(f.PositionY),
+			strconv.Itoa(f.Width) + "x" + strconv.Itoa(f.Height),
+			f.Gender.Gender, f.Age.AgeRange, f.Identify.Name,
+		})
+	}
+	return out.print(response, []string{"POSITION", "SIZE", "GENDER", "AGE", "IDENTITY"}, rows)
+}
+
+func runAlchemyNews(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("alchemy news", flag.ExitOnError)
+	start := fs.String("start", "now-1d", "start of the time range")
+	end := fs.String("end", "now", "end of the time range")
+	fields := fs.String("return", "enriched.url.title,original.url", "comma separated list of fields to return")
+	fs.Parse(args)
+
+	query := map[string]interface{}{"return": *fields}
+	for _, arg := range fs.Args() {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return errors.New(alchemyUsage)
+		}
+		query[kv[0]] = kv[1]
+	}
+	client, err := alchemy_data_news.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	result, err := client.GetNews(*start, *end, query)
+	if err != nil {
+		return err
+	}
+	// the shape of the result depends on the requested fields, so it is always printed as JSON
+	return output{json: true, w: out.w}.print(result, nil, nil)
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 3, 64)
+}
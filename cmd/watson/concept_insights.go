@@ -0,0 +1,107 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/concept_insights"
+)
+
+const ciUsage = "usage: watson ci [-graph g] graphs | corpora | concept <id> | search <label> | annotate [file]"
+
+// runCI implements the 'watson ci' family of subcommands for Concept Insights
+func runCI(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("ci", flag.ExitOnError)
+	graph := fs.String("graph", "/graphs/wikipedia/en-latest", "`graph` id used by search and annotate")
+	fs.Parse(args)
+	args = fs.Args()
+	if len(args) == 0 {
+		return errors.New(ciUsage)
+	}
+	client, err := concept_insights.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "graphs":
+		graphs, err := client.ListGraphs()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, g := range graphs.Graphs {
+			rows = append(rows, []string{g})
+		}
+		return out.print(graphs, []string{"GRAPH"}, rows)
+
+	case "corpora":
+		corpora, err := client.ListCorpora()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range corpora.Corpora {
+			rows = append(rows, []string{c.Id, c.Access})
+		}
+		return out.print(corpora, []string{"CORPUS", "ACCESS"}, rows)
+
+	case "concept":
+		if len(args) != 2 {
+			return errors.New(ciUsage)
+		}
+		concept, err := client.GetConcept(args[1])
+		if err != nil {
+			return err
+		}
+		return out.print(concept, []string{"ID", "LABEL", "LINK"}, [][]string{{concept.Id, concept.Label, concept.Link}})
+
+	case "search":
+		if len(args) < 2 {
+			return errors.New(ciUsage)
+		}
+		matches, err := client.SearchConceptByLabel(*graph, strings.Join(args[1:], " "), map[string]interface{}{"prefix": true})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range matches.Matches {
+			rows = append(rows, []string{c.Id, c.Label})
+		}
+		return out.print(matches, []string{"ID", "LABEL"}, rows)
+
+	case "annotate":
+		text, err := readInput(args[1:])
+		if err != nil {
+			return err
+		}
+		annotations, err := client.AnnotateText(*graph, bytes.NewReader(text), "text/plain")
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, a := range annotations.Annotations {
+			rows = append(rows, []string{a.Concept.Id, a.Concept.Label, formatScore(a.Score)})
+		}
+		return out.print(annotations, []string{"ID", "LABEL", "SCORE"}, rows)
+	}
+	return errors.New(ciUsage)
+}
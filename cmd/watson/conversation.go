@@ -0,0 +1,63 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/conversation"
+)
+
+const conversationUsage = "usage: watson converse <workspace_id> [text]"
+
+// runConversation implements 'watson converse <workspace_id> [text]', sending a single message to a workspace
+func runConversation(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(conversationUsage)
+	}
+	var text string
+	if len(args) > 1 {
+		text = strings.Join(args[1:], " ")
+	} else {
+		b, err := readInput(nil)
+		if err != nil {
+			return err
+		}
+		text = strings.TrimSpace(string(b))
+	}
+	client, err := conversation.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	response, err := client.Message(args[0], text)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, line := range response.Output.Text {
+		rows = append(rows, []string{"output", line, ""})
+	}
+	for _, intent := range response.Intents {
+		rows = append(rows, []string{"intent", intent.Intent, formatScore(intent.Confidence)})
+	}
+	for _, entity := range response.Entities {
+		rows = append(rows, []string{"entity", entity.Entity + ":" + entity.Value, ""})
+	}
+	return out.print(response, []string{"KIND", "VALUE", "CONFIDENCE"}, rows)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/dialog"
+)
+
+const dialogUsage = "usage: watson dialog list | create <name> dialog.xml | delete <id> | chat <id>"
+
+// runDialog implements the 'watson dialog' family of subcommands. 'chat' holds a conversation with a
+// dialog, sending each line read from stdin and printing the responses.
+func runDialog(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(dialogUsage)
+	}
+	client, err := dialog.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		dialogs, err := client.ListDialogs()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, d := range dialogs {
+			rows = append(rows, []string{d.DialogId, d.Name})
+		}
+		return out.print(dialogs, []string{"ID", "NAME"}, rows)
+
+	case "create":
+		if len(args) != 3 {
+			return errors.New(dialogUsage)
+		}
+		f, err := os.Open(args[2])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		id, err := client.CreateDialog(args[1], filepath.Base(args[2]), f)
+		if err != nil {
+			return err
+		}
+		return out.print(dialog.Dialog{DialogId: id, Name: args[1]}, []string{"ID", "NAME"}, [][]string{{id, args[1]}})
+
+	case "delete":
+		if len(args) != 2 {
+			return errors.New(dialogUsage)
+		}
+		return client.DeleteDialog(args[1])
+
+	case "chat":
+		if len(args) != 2 {
+			return errors.New(dialogUsage)
+		}
+		response, err := client.StartConversation(args[1])
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		for {
+			if err := printDialogResponse(out, response); err != nil {
+				return err
+			}
+			if !scanner.Scan() {
+				return scanner.Err()
+			}
+			response, err = client.UpdateConversation(args[1], response.ConversationId, response.ClientId, scanner.Text())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return errors.New(dialogUsage)
+}
+
+func printDialogResponse(out output, r dialog.ConversationResponse) error {
+	return out.print(r, nil, [][]string{{strings.Join(r.Response, " ")}})
+}
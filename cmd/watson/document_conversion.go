@@ -0,0 +1,70 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/document_conversion"
+)
+
+// conversionTargets maps the -to values to the service's conversion targets
+var conversionTargets = map[string]string{
+	"answers": document_conversion.AnswerUnits,
+	"html":    document_conversion.NormalizedHtml,
+	"text":    document_conversion.NormalizedText,
+}
+
+// runConvert implements 'watson convert [-to answers|html|text] [-type content-type] [file]'
+func runConvert(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := fs.String("to", "text", "conversion target: answers, html or text")
+	contentType := fs.String("type", "", "content type of the document; derived from the file name or contents if empty")
+	fs.Parse(args)
+
+	target, ok := conversionTargets[*to]
+	if !ok {
+		return errors.New("unknown conversion target " + *to + "; use \"answers\", \"html\" or \"text\"")
+	}
+	doc, err := readInput(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(*contentType) == 0 && fs.NArg() > 0 {
+		*contentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(fs.Arg(0))))
+	}
+	if len(*contentType) == 0 {
+		*contentType = http.DetectContentType(doc)
+	}
+	client, err := document_conversion.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	converted, err := client.Convert(target, nil, bytes.NewReader(doc), *contentType)
+	if err != nil {
+		return err
+	}
+	// the converted document is written as returned by the service (JSON for answer units)
+	_, err = out.w.Write(converted)
+	return err
+}
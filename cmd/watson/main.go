@@ -0,0 +1,140 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Command watson is a command-line client for the Watson Developer Cloud services.
+
+Usage:
+
+	watson [global flags] <command> [command flags] [arguments]
+
+Commands:
+
+	tone       analyze the tone of text read from a file or stdin
+	translate  translate text read from a file or stdin
+	nlc        manage and query Natural Language Classifier instances
+	tts        synthesize speech from text
+	stt        transcribe an audio file
+	alchemy    analyze text and images, or search news, with the AlchemyAPI services
+	ci         search and annotate with Concept Insights graphs
+	converse   send a message to a Conversation workspace
+	convert    convert a document with Document Conversion
+	dialog     manage and chat with Dialog instances
+	pi         compute a Personality Insights profile of text
+	rr         manage Retrieve and Rank clusters and rankers
+	vi         summarize images with Visual Insights
+	vr         manage and query Visual Recognition classifiers
+
+Global flags:
+
+	-url, -username, -password  service credentials; if not set, $VCAP_SERVICES is used
+	-output                     output format, "table" (default) or "json"
+	-opt-out                    send the X-Watson-Learning-Opt-Out header
+	-dry-run                    print equivalent curl commands instead of calling the service
+
+Examples:
+
+	watson tone < letter.txt
+	watson translate -to es < notice.txt
+	watson tts -voice en-US_AllisonVoice -o out.wav "Hello world"
+	watson stt speech.flac
+	watson nlc train -name tickets data.csv
+	watson alchemy keywords http://www.ibm.com/watson
+	watson convert -to answers manual.pdf
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+type command struct {
+	description string
+	run         func(cfg watson.Config, out output, args []string) error
+}
+
+var commands = map[string]command{
+	"tone":      {"analyze the tone of text read from a file or stdin", runTone},
+	"translate": {"translate text read from a file or stdin", runTranslate},
+	"nlc":       {"manage and query Natural Language Classifier instances", runNLC},
+	"tts":       {"synthesize speech from text", runTTS},
+	"stt":       {"transcribe an audio file", runSTT},
+	"alchemy":   {"analyze text and images, or search news, with the AlchemyAPI services", runAlchemy},
+	"ci":        {"search and annotate with Concept Insights graphs", runCI},
+	"converse":  {"send a message to a Conversation workspace", runConversation},
+	"convert":   {"convert a document with Document Conversion", runConvert},
+	"dialog":    {"manage and chat with Dialog instances", runDialog},
+	"pi":        {"compute a Personality Insights profile of text", runPI},
+	"rr":        {"manage Retrieve and Rank clusters and rankers", runRR},
+	"vi":        {"summarize images with Visual Insights", runVI},
+	"vr":        {"manage and query Visual Recognition classifiers", runVR},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: watson [global flags] <command> [command flags] [arguments]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintf(os.Stderr, "\nglobal flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	var creds watson.Credentials
+	flag.StringVar(&creds.Url, "url", "", "service `url`; defaults to the service's public endpoint")
+	flag.StringVar(&creds.Username, "username", "", "service username; if empty, $VCAP_SERVICES is used")
+	flag.StringVar(&creds.Password, "password", "", "service password; if empty, $VCAP_SERVICES is used")
+	format := flag.String("output", "table", "output `format`: table or json")
+	optOut := flag.Bool("opt-out", false, "opt out of Watson request logging")
+	dryRun := flag.Bool("dry-run", false, "print curl commands instead of sending requests")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "watson: unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	out, err := newOutput(*format, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watson: %s\n", err)
+		os.Exit(2)
+	}
+
+	cfg := watson.Config{Credentials: creds, LearningOptOut: *optOut}
+	if *dryRun {
+		cfg.DryRun = os.Stdout
+	}
+	err = cmd.run(cfg, out, flag.Args()[1:])
+	if err != nil && err != watson.ErrDryRun {
+		fmt.Fprintf(os.Stderr, "watson %s: %s\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
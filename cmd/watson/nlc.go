@@ -0,0 +1,118 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	nlc "github.com/liviosoares/go-watson-sdk/watson/natural_language_classifier"
+)
+
+const nlcUsage = "usage: watson nlc list | train [-name n] [-language l] data.csv | status <id> | classify <id> [text] | delete <id>"
+
+// runNLC implements the 'watson nlc' family of subcommands
+func runNLC(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(nlcUsage)
+	}
+	client, err := nlc.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		classifiers, err := client.ListClassifiers()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range classifiers {
+			rows = append(rows, []string{c.ClassifierId, c.Name, c.Language, c.Created})
+		}
+		return out.print(classifiers, []string{"ID", "NAME", "LANGUAGE", "CREATED"}, rows)
+
+	case "train":
+		fs := flag.NewFlagSet("nlc train", flag.ExitOnError)
+		name := fs.String("name", "", "classifier name")
+		language := fs.String("language", "en", "classifier language")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return errors.New(nlcUsage)
+		}
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		status, err := client.CreateClassifier(nlc.ClassifierMetadata{Name: *name, Language: *language}, f)
+		if err != nil {
+			return err
+		}
+		return printClassifierStatus(out, status)
+
+	case "status":
+		if len(args) != 2 {
+			return errors.New(nlcUsage)
+		}
+		status, err := client.GetClassifierStatus(args[1])
+		if err != nil {
+			return err
+		}
+		return printClassifierStatus(out, status)
+
+	case "classify":
+		if len(args) < 2 {
+			return errors.New(nlcUsage)
+		}
+		var text string
+		if len(args) > 2 {
+			text = strings.Join(args[2:], " ")
+		} else {
+			b, err := readInput(nil)
+			if err != nil {
+				return err
+			}
+			text = string(b)
+		}
+		classification, err := client.Classify(args[1], text)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, class := range classification.Classes {
+			rows = append(rows, []string{class.ClassName, strconv.FormatFloat(class.Confidence, 'f', 3, 64)})
+		}
+		return out.print(classification, []string{"CLASS", "CONFIDENCE"}, rows)
+
+	case "delete":
+		if len(args) != 2 {
+			return errors.New(nlcUsage)
+		}
+		return client.DeleteClassifier(args[1])
+	}
+	return errors.New(nlcUsage)
+}
+
+func printClassifierStatus(out output, status nlc.ClassifierStatus) error {
+	rows := [][]string{{status.ClassifierId, status.Name, status.Status, status.StatusDescription}}
+	return out.print(status, []string{"ID", "NAME", "STATUS", "DESCRIPTION"}, rows)
+}
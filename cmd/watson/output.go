@@ -0,0 +1,68 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// output renders command results either as indented JSON or as a tab-aligned table.
+type output struct {
+	json bool
+	w    io.Writer
+}
+
+func newOutput(format string, w io.Writer) (output, error) {
+	switch format {
+	case "table":
+		return output{w: w}, nil
+	case "json":
+		return output{json: true, w: w}, nil
+	}
+	return output{}, errors.New("unknown output format " + format + "; use \"table\" or \"json\"")
+}
+
+// print writes v as JSON in json mode, and otherwise writes header and rows as a table.
+func (o output) print(v interface{}, header []string, rows [][]string) error {
+	if o.json {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 8, 2, ' ', 0)
+	if len(header) > 0 {
+		io.WriteString(tw, strings.Join(header, "\t")+"\n")
+	}
+	for _, row := range rows {
+		io.WriteString(tw, strings.Join(row, "\t")+"\n")
+	}
+	return tw.Flush()
+}
+
+// readInput returns the contents of the file named by the first argument, or of stdin if there are no
+// arguments (or the argument is "-").
+func readInput(args []string) ([]byte, error) {
+	if len(args) == 0 || args[0] == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(args[0])
+}
@@ -0,0 +1,48 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutput(t *testing.T) {
+	v := []map[string]interface{}{{"class_name": "weather", "confidence": 0.9}}
+	rows := [][]string{{"weather", "0.900"}}
+
+	var buf bytes.Buffer
+	out, err := newOutput("table", &buf)
+	if err != nil {
+		t.Errorf("newOutput() failed %#v\n", err)
+		return
+	}
+	out.print(v, []string{"CLASS", "CONFIDENCE"}, rows)
+	if want := "CLASS    CONFIDENCE\nweather  0.900\n"; buf.String() != want {
+		t.Errorf("table output wanted %q, got %q\n", want, buf.String())
+	}
+
+	buf.Reset()
+	out, _ = newOutput("json", &buf)
+	out.print(v, []string{"CLASS", "CONFIDENCE"}, rows)
+	if want := "[\n  {\n    \"class_name\": \"weather\",\n    \"confidence\": 0.9\n  }\n]\n"; buf.String() != want {
+		t.Errorf("json output wanted %q, got %q\n", want, buf.String())
+	}
+
+	if _, err := newOutput("yaml", &buf); err == nil {
+		t.Errorf("newOutput() accepted unknown format\n")
+	}
+}
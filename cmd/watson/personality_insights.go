@@ -0,0 +1,60 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/personality_insights"
+)
+
+// runPI implements 'watson pi [-lang l] [-type content-type] [file]'
+func runPI(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("pi", flag.ExitOnError)
+	language := fs.String("lang", "", "language of the input, en or es; detected by the service if empty")
+	contentType := fs.String("type", "text/plain; charset=utf-8", "content type of the input: plain text, HTML or JSON content items")
+	fs.Parse(args)
+
+	text, err := readInput(fs.Args())
+	if err != nil {
+		return err
+	}
+	client, err := personality_insights.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	profile, err := client.GetProfile(bytes.NewReader(text), *contentType, *language)
+	if err != nil {
+		return err
+	}
+
+	// the trait tree is flattened into one row per characteristic, indented by depth
+	var rows [][]string
+	var walk func(t personality_insights.TraitTree, depth int)
+	walk = func(t personality_insights.TraitTree, depth int) {
+		rows = append(rows, []string{strings.Repeat("  ", depth) + t.Name, t.Category, formatScore(t.Percentage)})
+		for _, child := range t.Children {
+			walk(child, depth+1)
+		}
+	}
+	for _, child := range profile.Tree.Children {
+		walk(child, 0)
+	}
+	return out.print(profile, []string{"TRAIT", "CATEGORY", "PERCENTAGE"}, rows)
+}
@@ -0,0 +1,191 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/retrieve_and_rank"
+)
+
+const rrUsage = "usage: watson rr clusters | create-cluster <name> [size] | delete-cluster <id> | configs <cluster> | upload-config <cluster> <name> config.zip | " +
+	"collections <cluster> | search <cluster> <collection> <query> | rankers | create-ranker <name> training.csv | ranker-status <id> | rank <id> answers.csv | delete-ranker <id>"
+
+// runRR implements the 'watson rr' family of subcommands for Retrieve and Rank
+func runRR(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(rrUsage)
+	}
+	client, err := retrieve_and_rank.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "clusters":
+		clusters, err := client.ListClusters()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range clusters.Clusters {
+			rows = append(rows, []string{c.Id, c.Name, c.Size, c.Status})
+		}
+		return out.print(clusters, []string{"ID", "NAME", "SIZE", "STATUS"}, rows)
+
+	case "create-cluster":
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New(rrUsage)
+		}
+		size := 0
+		if len(args) == 3 {
+			size, err = strconv.Atoi(args[2])
+			if err != nil {
+				return err
+			}
+		}
+		c, err := client.CreateCluster(args[1], size)
+		if err != nil {
+			return err
+		}
+		return out.print(c, []string{"ID", "NAME", "SIZE", "STATUS"}, [][]string{{c.Id, c.Name, c.Size, c.Status}})
+
+	case "delete-cluster":
+		if len(args) != 2 {
+			return errors.New(rrUsage)
+		}
+		return client.DeleteCluster(args[1])
+
+	case "configs":
+		if len(args) != 2 {
+			return errors.New(rrUsage)
+		}
+		configs, err := client.ListConfigs(args[1])
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range configs.Configs {
+			rows = append(rows, []string{c})
+		}
+		return out.print(configs, []string{"CONFIG"}, rows)
+
+	case "upload-config":
+		if len(args) != 4 {
+			return errors.New(rrUsage)
+		}
+		f, err := os.Open(args[3])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return client.UploadConfig(args[1], args[2], f)
+
+	case "collections":
+		if len(args) != 2 {
+			return errors.New(rrUsage)
+		}
+		// Solr responses are written as returned by the service
+		b, err := client.ListCollections(args[1], nil)
+		if err != nil {
+			return err
+		}
+		_, err = out.w.Write(b)
+		return err
+
+	case "search":
+		if len(args) < 4 {
+			return errors.New(rrUsage)
+		}
+		b, err := client.Search(args[1], args[2], strings.Join(args[3:], " "), map[string]interface{}{"wt": "json"})
+		if err != nil {
+			return err
+		}
+		_, err = out.w.Write(b)
+		return err
+
+	case "rankers":
+		rankers, err := client.ListRankers()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, r := range rankers.Rankers {
+			rows = append(rows, []string{r.RankerId, r.Name, r.Created})
+		}
+		return out.print(rankers, []string{"ID", "NAME", "CREATED"}, rows)
+
+	case "create-ranker":
+		if len(args) != 3 {
+			return errors.New(rrUsage)
+		}
+		f, err := os.Open(args[2])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		ranker, err := client.CreateRanker(args[1], f)
+		if err != nil {
+			return err
+		}
+		return printRanker(out, ranker)
+
+	case "ranker-status":
+		if len(args) != 2 {
+			return errors.New(rrUsage)
+		}
+		ranker, err := client.GetRanker(args[1])
+		if err != nil {
+			return err
+		}
+		return printRanker(out, ranker)
+
+	case "rank":
+		if len(args) != 3 {
+			return errors.New(rrUsage)
+		}
+		f, err := os.Open(args[2])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		ranked, err := client.Rank(args[1], f)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, a := range ranked.Answers {
+			rows = append(rows, []string{a.AnswerId, formatScore(a.Score), formatScore(a.Confidence)})
+		}
+		return out.print(ranked, []string{"ANSWER", "SCORE", "CONFIDENCE"}, rows)
+
+	case "delete-ranker":
+		if len(args) != 2 {
+			return errors.New(rrUsage)
+		}
+		return client.DeleteRanker(args[1])
+	}
+	return errors.New(rrUsage)
+}
+
+func printRanker(out output, r retrieve_and_rank.Ranker) error {
+	rows := [][]string{{r.RankerId, r.Name, r.Status, r.StatusDescription}}
+	return out.print(r, []string{"ID", "NAME", "STATUS", "DESCRIPTION"}, rows)
+}
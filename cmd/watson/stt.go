@@ -0,0 +1,91 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text"
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text/audio"
+)
+
+// runSTT implements 'watson stt [-model m] [-content-type t] [-captions srt|vtt] file'
+func runSTT(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("stt", flag.ExitOnError)
+	model := fs.String("model", "", "recognition model, e.g. en-US_BroadbandModel")
+	contentType := fs.String("content-type", "", "audio content type; detected from the file header if empty")
+	timestamps := fs.Bool("timestamps", false, "request per-word timestamps")
+	captions := fs.String("captions", "", "write the transcript as srt or vtt subtitles")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: watson stt [-model m] [-content-type t] [-captions srt|vtt] file")
+	}
+	if *captions != "" && *captions != "srt" && *captions != "vtt" {
+		return errors.New("unknown caption format " + *captions)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var in io.Reader = f
+	if len(*contentType) == 0 {
+		var format audio.Format
+		format, in, err = audio.Sniff(f)
+		if err != nil {
+			return errors.New("cannot detect content type of " + fs.Arg(0) + "; use -content-type")
+		}
+		*contentType = format.ContentType()
+	}
+
+	client, err := speech_to_text.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	event, err := client.Recognize(context.Background(), in, *contentType, speech_to_text.RecognizeOptions{
+		Model:      *model,
+		Continuous: true,
+		Timestamps: *timestamps || len(*captions) > 0,
+	})
+	if err != nil {
+		return err
+	}
+	if len(*captions) > 0 {
+		transcript := speech_to_text.NewTranscript(speech_to_text.TranscriptOptions{})
+		transcript.Add(event)
+		if *captions == "srt" {
+			return transcript.WriteSRT(out.w)
+		}
+		return transcript.WriteVTT(out.w)
+	}
+
+	var rows [][]string
+	for i, result := range event.Results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		alt := result.Alternatives[0]
+		rows = append(rows, []string{strconv.Itoa(event.ResultIndex + i), strconv.FormatFloat(alt.Confidence, 'f', 3, 64), alt.Transcript})
+	}
+	return out.print(event, []string{"#", "CONFIDENCE", "TRANSCRIPT"}, rows)
+}
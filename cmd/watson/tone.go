@@ -0,0 +1,64 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/tone_analyzer"
+)
+
+// runTone implements 'watson tone [-sentences] [file]'
+func runTone(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("tone", flag.ExitOnError)
+	sentences := fs.Bool("sentences", false, "include per-sentence analysis")
+	tones := fs.String("tones", "", "comma separated list of tone categories (emotion, language, social)")
+	fs.Parse(args)
+
+	text, err := readInput(fs.Args())
+	if err != nil {
+		return err
+	}
+	client, err := tone_analyzer.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	options := map[string]interface{}{"sentences": *sentences}
+	if len(*tones) > 0 {
+		options["tones"] = *tones
+	}
+	analysis, err := client.Tone(string(text), options)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, category := range analysis.DocumentTone.ToneCategories {
+		for _, tone := range category.Tones {
+			rows = append(rows, []string{"document", category.CategoryName, tone.ToneName, strconv.FormatFloat(tone.Score, 'f', 3, 64)})
+		}
+	}
+	for _, sentence := range analysis.SentencesTone {
+		for _, category := range sentence.ToneCategories {
+			for _, tone := range category.Tones {
+				rows = append(rows, []string{"sentence " + strconv.Itoa(sentence.SentenceId), category.CategoryName, tone.ToneName, strconv.FormatFloat(tone.Score, 'f', 3, 64)})
+			}
+		}
+	}
+	return out.print(analysis, []string{"SCOPE", "CATEGORY", "TONE", "SCORE"}, rows)
+}
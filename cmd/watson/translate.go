@@ -0,0 +1,56 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/language_translation"
+)
+
+// runTranslate implements 'watson translate [-from lang] -to lang [-model id] [file]'
+func runTranslate(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	from := fs.String("from", "en", "source language")
+	to := fs.String("to", "", "target language")
+	model := fs.String("model", "", "translation model id; overrides -from and -to")
+	fs.Parse(args)
+
+	if len(*to) == 0 && len(*model) == 0 {
+		return errors.New("one of -to or -model is required")
+	}
+	text, err := readInput(fs.Args())
+	if err != nil {
+		return err
+	}
+	client, err := language_translation.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	response, err := client.Translate(string(text), *from, *to, *model)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for i, t := range response.Translations {
+		rows = append(rows, []string{strconv.Itoa(i), t.Translation})
+	}
+	return out.print(response, []string{"#", "TRANSLATION"}, rows)
+}
@@ -0,0 +1,129 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/text_to_speech"
+)
+
+// audioTypes maps output file extensions to the corresponding Accept values
+var audioTypes = map[string]string{
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".ogg":  "audio/ogg; codecs=opus",
+	".opus": "audio/ogg; codecs=opus",
+}
+
+// runTTS implements 'watson tts [-voice v | -lang l] [-o out.wav] [-list] [text...]'
+func runTTS(cfg watson.Config, out output, args []string) error {
+	fs := flag.NewFlagSet("tts", flag.ExitOnError)
+	voice := fs.String("voice", "", "voice to synthesize with, e.g. en-US_AllisonVoice")
+	lang := fs.String("lang", "", "language `tag` to select a voice for, e.g. en-GB, if -voice is empty")
+	output := fs.String("o", "", "output `file`; audio is written to stdout if empty")
+	accept := fs.String("accept", "", "audio format; derived from the output file extension if empty")
+	customization := fs.String("customization", "", "custom voice model id")
+	list := fs.Bool("list", false, "list the available voices instead of synthesizing")
+	fs.Parse(args)
+
+	client, err := text_to_speech.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	if *list {
+		voices, err := client.ListVoices()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, v := range voices.Voices {
+			rows = append(rows, []string{v.Name, v.Language, v.Gender, strconv.FormatBool(v.Customizable)})
+		}
+		return out.print(voices, []string{"NAME", "LANGUAGE", "GENDER", "CUSTOMIZABLE"}, rows)
+	}
+
+	if len(*voice) == 0 && len(*lang) > 0 {
+		voices, err := client.ListVoices()
+		if err != nil {
+			return err
+		}
+		v, err := voices.Select(*lang, "")
+		if err != nil {
+			return err
+		}
+		*voice = v.Name
+	}
+
+	var text string
+	if fs.NArg() > 0 {
+		text = strings.Join(fs.Args(), " ")
+	} else {
+		b, err := readInput(nil)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+	if len(*accept) == 0 {
+		*accept = audioTypes[strings.ToLower(filepath.Ext(*output))]
+		if len(*accept) == 0 {
+			*accept = "audio/wav"
+		}
+	}
+	// audio goes to a temporary file that only replaces the output once synthesis succeeds, so a failed
+	// or dry run leaves no partial file behind
+	w := os.Stdout
+	if len(*output) > 0 {
+		w, err = ioutil.TempFile(filepath.Dir(*output), "."+filepath.Base(*output)+".")
+		if err != nil {
+			return err
+		}
+	}
+	if len(text) > text_to_speech.DefaultMaxChunkSize {
+		// too long for a single call; synthesized in chunks
+		var audio []byte
+		audio, err = client.SynthesizeLong(context.Background(), text, text_to_speech.LongFormOptions{
+			Voice:           *voice,
+			Accept:          *accept,
+			CustomizationId: *customization,
+		})
+		if err == nil {
+			_, err = w.Write(audio)
+		}
+	} else {
+		_, err = client.SynthesizeTo(context.Background(), w, text, *voice, *accept, *customization)
+	}
+	if len(*output) > 0 {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(w.Name(), *output)
+		}
+		if err != nil {
+			os.Remove(w.Name())
+		}
+	}
+	return err
+}
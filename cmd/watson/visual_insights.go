@@ -0,0 +1,70 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/visual_insights"
+)
+
+const viUsage = "usage: watson vi classifiers | summarize <images.zip>"
+
+// runVI implements the 'watson vi' family of subcommands for Visual Insights
+func runVI(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(viUsage)
+	}
+	client, err := visual_insights.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "classifiers":
+		list, err := client.ListClassifiers()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range list.Classifiers {
+			rows = append(rows, []string{c.Name})
+		}
+		return out.print(list, []string{"NAME"}, rows)
+
+	case "summarize":
+		if len(args) != 2 {
+			return errors.New(viUsage)
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		summary, err := client.Summarize(f)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, s := range summary.Summary {
+			rows = append(rows, []string{s.Name, formatScore(s.Score)})
+		}
+		return out.print(summary, []string{"CLASSIFIER", "SCORE"}, rows)
+	}
+	return errors.New(viUsage)
+}
@@ -0,0 +1,126 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/visual_recognition"
+)
+
+const vrUsage = "usage: watson vr list | show <id> | create -positive p.zip -negative n.zip <name> | classify [-classifiers id,...] <image> | delete <id>"
+
+// runVR implements the 'watson vr' family of subcommands for Visual Recognition
+func runVR(cfg watson.Config, out output, args []string) error {
+	if len(args) == 0 {
+		return errors.New(vrUsage)
+	}
+	client, err := visual_recognition.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		list, err := client.ListClassifiers()
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range list.Classifiers {
+			rows = append(rows, []string{c.Id, c.Name, c.Status})
+		}
+		return out.print(list, []string{"ID", "NAME", "STATUS"}, rows)
+
+	case "show":
+		if len(args) != 2 {
+			return errors.New(vrUsage)
+		}
+		classifier, err := client.GetClassifier(args[1])
+		if err != nil {
+			return err
+		}
+		return printVRClassifier(out, classifier)
+
+	case "create":
+		fs := flag.NewFlagSet("vr create", flag.ExitOnError)
+		positive := fs.String("positive", "", "zip `file` of positive example images")
+		negative := fs.String("negative", "", "zip `file` of negative example images")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 || len(*positive) == 0 || len(*negative) == 0 {
+			return errors.New(vrUsage)
+		}
+		p, err := os.Open(*positive)
+		if err != nil {
+			return err
+		}
+		defer p.Close()
+		n, err := os.Open(*negative)
+		if err != nil {
+			return err
+		}
+		defer n.Close()
+		classifier, err := client.CreateClassifier(fs.Arg(0), p, n)
+		if err != nil {
+			return err
+		}
+		return printVRClassifier(out, classifier)
+
+	case "classify":
+		fs := flag.NewFlagSet("vr classify", flag.ExitOnError)
+		ids := fs.String("classifiers", "", "comma separated list of classifier ids; all classifiers if empty")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return errors.New(vrUsage)
+		}
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		var classifiers []string
+		if len(*ids) > 0 {
+			classifiers = strings.Split(*ids, ",")
+		}
+		result, err := client.Classify(f, classifiers)
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, image := range result.Images {
+			for _, score := range image.Scores {
+				rows = append(rows, []string{image.Image, score.Name, formatScore(score.Score)})
+			}
+		}
+		return out.print(result, []string{"IMAGE", "CLASSIFIER", "SCORE"}, rows)
+
+	case "delete":
+		if len(args) != 2 {
+			return errors.New(vrUsage)
+		}
+		return client.DeleteClassifier(args[1])
+	}
+	return errors.New(vrUsage)
+}
+
+func printVRClassifier(out output, c visual_recognition.Classifier) error {
+	rows := [][]string{{c.Id, c.Name, c.Status}}
+	return out.print(c, []string{"ID", "NAME", "STATUS"}, rows)
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cache is an LRU cache of replies, bounded both in number of entries and in bytes, whose entries expire after
+// a fixed time. A nil *cache is valid, and caches nothing.
+type cache struct {
+	mu       sync.Mutex
+	size     int
+	maxBytes int
+	maxReply int
+	ttl      time.Duration
+	lru      *list.List
+	entries  map[string]*list.Element
+	// bytes is the total size of the cached replies
+	bytes int
+}
+
+type cacheEntry struct {
+	key     string
+	reply   reply
+	expires time.Time
+}
+
+func newCache(size int, maxBytes int, maxReply int, ttl time.Duration) *cache {
+	return &cache{size: size, maxBytes: maxBytes, maxReply: maxReply, ttl: ttl, lru: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *cache) get(key string) (reply, bool) {
+	if c == nil {
+		return reply{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return reply{}, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(e)
+		return reply{}, false
+	}
+	c.lru.MoveToFront(e)
+	return entry.reply, true
+}
+
+func (c *cache) put(key string, r reply) {
+	if c == nil {
+		return
+	}
+	if len(r.body) > c.maxReply {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, reply: r, expires: time.Now().Add(c.ttl)})
+	c.bytes += len(r.body)
+	for c.lru.Len() > c.size || c.bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops the entry e; c.mu must be held
+func (c *cache) remove(e *list.Element) {
+	entry := e.Value.(*cacheEntry)
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	c.bytes -= len(entry.reply.body)
+}
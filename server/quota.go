@@ -0,0 +1,73 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota decides whether a tenant may make another call.
+type Quota interface {
+	Allow(tenant string) bool
+}
+
+// RateQuota allows each tenant a fixed number of calls per time window.
+type RateQuota struct {
+	limit  int
+	window time.Duration
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+	swept   time.Time
+}
+
+type quotaWindow struct {
+	start time.Time
+	calls int
+}
+
+// NewRateQuota returns a Quota allowing each tenant at most limit calls in every window.
+func NewRateQuota(limit int, window time.Duration) *RateQuota {
+	return &RateQuota{limit: limit, window: window, now: time.Now, windows: make(map[string]*quotaWindow)}
+}
+
+func (q *RateQuota) Allow(tenant string) bool {
+	now := q.now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// once per window, forget the tenants whose windows have expired so the map does not keep growing
+	if now.Sub(q.swept) >= q.window {
+		for t, w := range q.windows {
+			if now.Sub(w.start) >= q.window {
+				delete(q.windows, t)
+			}
+		}
+		q.swept = now
+	}
+	w, ok := q.windows[tenant]
+	if !ok || now.Sub(w.start) >= q.window {
+		w = &quotaWindow{start: now}
+		q.windows[tenant] = w
+	}
+	if w.calls >= q.limit {
+		return false
+	}
+	w.calls++
+	return true
+}
@@ -0,0 +1,52 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text"
+)
+
+// recognizeHandler serves GET /v1/recognize, relaying websocket sessions to the Speech to Text service.
+func (s *Server) recognizeHandler() http.Handler {
+	cfg := speech_to_text.RelayConfig{
+		Authenticate:       s.cfg.Authenticate,
+		MaxSessionsPerUser: s.cfg.MaxRecognizeSessions,
+		MaxSessionLength:   s.cfg.MaxRecognizeLength,
+		CheckOrigin:        s.cfg.RecognizeOrigin,
+		Done: func(r speech_to_text.RelayRecord) {
+			record := AuditRecord{
+				Time:       r.Start,
+				Tenant:     r.User,
+				RemoteAddr: r.RemoteAddr,
+				Operation:  "recognize",
+				Status:     r.Status,
+				Duration:   r.Duration,
+				BytesIn:    r.BytesIn,
+				BytesOut:   r.BytesOut,
+			}
+			if r.Err != nil {
+				record.Error = r.Err.Error()
+			}
+			s.cfg.Audit(record)
+		},
+	}
+	if s.cfg.Quota != nil {
+		cfg.Allow = s.cfg.Quota.Allow
+	}
+	return s.stt.NewRelayHandler(cfg)
+}
@@ -0,0 +1,366 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package server implements an HTTP gateway exposing a curated set of Watson operations as an internal REST
+service. The gateway holds the Watson credentials, so that its callers never see them; callers are
+authenticated through a pluggable hook, subject to per-tenant quotas, and every call is audited.
+
+Endpoints:
+
+	POST /v1/tone        text/plain body; query parameters are passed on to the Tone Analyzer
+	POST /v1/translate   JSON {"text", "source", "target", "model_id"}
+	POST /v1/classify    JSON {"classifier_id", "text"}
+	POST /v1/synthesize  JSON {"text", "voice", "accept", "customization_id"}; replies with audio
+	GET  /v1/recognize   websocket; see speech_to_text.RelayHandler
+
+Results of tone, translate, classify and synthesize calls are cached, since they are deterministic
+for a given input. The cache is bounded in entries and in bytes; replies larger than Config.CacheMaxReply,
+such as long synthesized audio, are not cached.
+*/
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/language_translation"
+	nlc "github.com/liviosoares/go-watson-sdk/watson/natural_language_classifier"
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text"
+	"github.com/liviosoares/go-watson-sdk/watson/text_to_speech"
+	"github.com/liviosoares/go-watson-sdk/watson/tone_analyzer"
+)
+
+// Authenticator identifies the tenant making a request. A non-nil error rejects the request.
+type Authenticator func(r *http.Request) (tenant string, err error)
+
+// AuditRecord describes a single call made through the gateway.
+type AuditRecord struct {
+	Time       time.Time     `json:"time"`
+	Tenant     string        `json:"tenant,omitempty"`
+	RemoteAddr string        `json:"remote_addr"`
+	Operation  string        `json:"operation"`
+	Status     int           `json:"status"`
+	Duration   time.Duration `json:"duration"`
+	Cached     bool          `json:"cached,omitempty"`
+	BytesIn    int64         `json:"bytes_in"`
+	BytesOut   int64         `json:"bytes_out"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Config configures a gateway Server. Each service is enabled by providing its watson.Config; services
+// left nil are not exposed.
+type Config struct {
+	Tone         *watson.Config
+	Translation  *watson.Config
+	Classifier   *watson.Config
+	TextToSpeech *watson.Config
+	SpeechToText *watson.Config
+
+	// Authenticate is required, and is called for every request.
+	Authenticate Authenticator
+	// Quota limits the calls made by each tenant; nil means no limit.
+	Quota Quota
+	// CacheSize is the maximum number of cached replies; zero disables caching.
+	CacheSize int
+	// CacheBytes is the maximum total size of the cached replies; defaults to 64MB.
+	CacheBytes int
+	// CacheMaxReply is the size of the largest reply which is cached, such as synthesized audio; defaults to 1MB.
+	CacheMaxReply int
+	// CacheTTL is the lifetime of cached replies; defaults to one hour.
+	CacheTTL time.Duration
+	// MaxBodySize limits the size of request bodies; defaults to 1MB.
+	MaxBodySize int64
+	// MaxRecognizeSessions limits the concurrent recognition sessions of each tenant; zero means no limit.
+	MaxRecognizeSessions int
+	// MaxRecognizeLength limits the duration of recognition sessions; zero means no limit.
+	MaxRecognizeLength time.Duration
+	// RecognizeOrigin decides whether browser pages of the Origin of a request may open recognition sessions;
+	// nil only allows pages served by the gateway host (see speech_to_text.RelayConfig.CheckOrigin).
+	RecognizeOrigin func(r *http.Request) bool
+	// Audit is called once for every request; defaults to logging the record as JSON.
+	Audit func(AuditRecord)
+}
+
+// Server is an http.Handler serving the gateway endpoints.
+type Server struct {
+	cfg   Config
+	mux   *http.ServeMux
+	cache *cache
+
+	tone        tone_analyzer.Client
+	translation language_translation.Client
+	classifier  nlc.Client
+	tts         text_to_speech.Client
+	stt         speech_to_text.Client
+}
+
+// New creates a gateway server, connecting to each of the configured services.
+func New(cfg Config) (*Server, error) {
+	if cfg.Authenticate == nil {
+		return nil, errors.New("server: an Authenticate hook is required")
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = time.Hour
+	}
+	if cfg.CacheBytes == 0 {
+		cfg.CacheBytes = 64 << 20
+	}
+	if cfg.CacheMaxReply == 0 {
+		cfg.CacheMaxReply = 1 << 20
+	}
+	if cfg.MaxBodySize == 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.Audit == nil {
+		cfg.Audit = logAudit
+	}
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	if cfg.CacheSize > 0 {
+		s.cache = newCache(cfg.CacheSize, cfg.CacheBytes, cfg.CacheMaxReply, cfg.CacheTTL)
+	}
+
+	var err error
+	if cfg.Tone != nil {
+		if s.tone, err = tone_analyzer.NewClient(*cfg.Tone); err != nil {
+			return nil, err
+		}
+		s.handle("/v1/tone", "tone", s.serveTone)
+	}
+	if cfg.Translation != nil {
+		if s.translation, err = language_translation.NewClient(*cfg.Translation); err != nil {
+			return nil, err
+		}
+		s.handle("/v1/translate", "translate", s.serveTranslate)
+	}
+	if cfg.Classifier != nil {
+		if s.classifier, err = nlc.NewClient(*cfg.Classifier); err != nil {
+			return nil, err
+		}
+		s.handle("/v1/classify", "classify", s.serveClassify)
+	}
+	if cfg.TextToSpeech != nil {
+		if s.tts, err = text_to_speech.NewClient(*cfg.TextToSpeech); err != nil {
+			return nil, err
+		}
+		s.handle("/v1/synthesize", "synthesize", s.serveSynthesize)
+	}
+	if cfg.SpeechToText != nil {
+		if s.stt, err = speech_to_text.NewClient(*cfg.SpeechToText); err != nil {
+			return nil, err
+		}
+		s.mux.Handle("/v1/recognize", s.recognizeHandler())
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// reply is what an operation produces; it is what gets cached.
+type reply struct {
+	contentType string
+	body        []byte
+}
+
+// operation performs a call on behalf of a tenant, given the request body.
+type operation func(r *http.Request, body []byte) (reply, error)
+
+// handle registers op under path, wrapping it with authentication, quota, caching and auditing.
+func (s *Server) handle(path, name string, op operation) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		record := AuditRecord{Time: time.Now(), RemoteAddr: r.RemoteAddr, Operation: name}
+		defer func() {
+			record.Duration = time.Since(record.Time)
+			s.cfg.Audit(record)
+		}()
+		fail := func(code int, err error) {
+			record.Status = code
+			record.Error = err.Error()
+			writeError(w, code, err)
+		}
+
+		if r.Method != "POST" {
+			fail(http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		tenant, err := s.cfg.Authenticate(r)
+		if err != nil {
+			fail(http.StatusUnauthorized, err)
+			return
+		}
+		record.Tenant = tenant
+		if s.cfg.Quota != nil && !s.cfg.Quota.Allow(tenant) {
+			fail(http.StatusTooManyRequests, errors.New("quota exceeded"))
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodySize))
+		if err != nil {
+			fail(http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		record.BytesIn = int64(len(body))
+
+		key := cacheKey(name, r.URL.Query(), body)
+		rep, ok := s.cache.get(key)
+		if ok {
+			record.Cached = true
+		} else {
+			rep, err = op(r, body)
+			if err != nil {
+				// client errors are passed through, except for authentication failures, which mean the
+				// gateway's own Watson credentials are wrong
+				code := http.StatusBadGateway
+				if werr, ok := err.(*watson.WatsonError); ok && werr.Code >= 400 && werr.Code < 500 &&
+					werr.Code != http.StatusUnauthorized && werr.Code != http.StatusForbidden {
+					code = werr.Code
+				} else if _, ok := err.(*badRequest); ok {
+					code = http.StatusBadRequest
+				}
+				fail(code, err)
+				return
+			}
+			s.cache.put(key, rep)
+		}
+		record.Status = http.StatusOK
+		record.BytesOut = int64(len(rep.body))
+		w.Header().Set("Content-Type", rep.contentType)
+		w.Write(rep.body)
+	})
+}
+
+func (s *Server) serveTone(r *http.Request, body []byte) (reply, error) {
+	options := make(map[string]interface{})
+	for k, v := range r.URL.Query() {
+		options[k] = v[0]
+	}
+	analysis, err := s.tone.Tone(string(body), options)
+	if err != nil {
+		return reply{}, err
+	}
+	return jsonReply(analysis)
+}
+
+func (s *Server) serveTranslate(r *http.Request, body []byte) (reply, error) {
+	var req struct {
+		Text    string `json:"text"`
+		Source  string `json:"source"`
+		Target  string `json:"target"`
+		ModelId string `json:"model_id"`
+	}
+	if err := decodeRequest(body, &req); err != nil {
+		return reply{}, err
+	}
+	response, err := s.translation.Translate(req.Text, req.Source, req.Target, req.ModelId)
+	if err != nil {
+		return reply{}, err
+	}
+	return jsonReply(response)
+}
+
+func (s *Server) serveClassify(r *http.Request, body []byte) (reply, error) {
+	var req struct {
+		ClassifierId string `json:"classifier_id"`
+		Text         string `json:"text"`
+	}
+	if err := decodeRequest(body, &req); err != nil {
+		return reply{}, err
+	}
+	if len(req.ClassifierId) == 0 {
+		return reply{}, &badRequest{"classifier_id is required"}
+	}
+	// classifier ids are used as path components
+	classification, err := s.classifier.Classify(url.PathEscape(req.ClassifierId), req.Text)
+	if err != nil {
+		return reply{}, err
+	}
+	return jsonReply(classification)
+}
+
+func (s *Server) serveSynthesize(r *http.Request, body []byte) (reply, error) {
+	var req struct {
+		Text            string `json:"text"`
+		Voice           string `json:"voice"`
+		Accept          string `json:"accept"`
+		CustomizationId string `json:"customization_id"`
+	}
+	if err := decodeRequest(body, &req); err != nil {
+		return reply{}, err
+	}
+	if len(req.Accept) == 0 {
+		req.Accept = "audio/wav"
+	}
+	// the synthesis is canceled if the client goes away
+	audio, err := s.tts.SynthesizeReader(r.Context(), req.Text, req.Voice, req.Accept, req.CustomizationId)
+	if err != nil {
+		return reply{}, err
+	}
+	defer audio.Close()
+	b, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return reply{}, err
+	}
+	return reply{contentType: req.Accept, body: b}, nil
+}
+
+// badRequest reports malformed gateway requests
+type badRequest struct {
+	message string
+}
+
+func (e *badRequest) Error() string {
+	return e.message
+}
+
+func decodeRequest(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &badRequest{"invalid JSON request: " + err.Error()}
+	}
+	return nil
+}
+
+func jsonReply(v interface{}) (reply, error) {
+	b, err := json.Marshal(v)
+	return reply{contentType: "application/json", body: b}, err
+}
+
+// writeError replies with a JSON error body, in the same format used by the Watson services
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "error": err.Error()})
+}
+
+func cacheKey(name string, query url.Values, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(name + "\x00" + query.Encode() + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func logAudit(record AuditRecord) {
+	b, _ := json.Marshal(record)
+	log.Println(string(bytes.TrimSpace(b)))
+}
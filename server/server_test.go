@@ -0,0 +1,187 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+func TestGateway(t *testing.T) {
+	calls := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get(watson.LearningOptOutHeader) != "true" {
+			t.Errorf("backend request missing opt-out header\n")
+		}
+		w.Write([]byte(`{"translations": [{"translation": "hola"}], "word_count": 1}`))
+	}))
+	defer backend.Close()
+
+	var mu sync.Mutex
+	var records []AuditRecord
+	s, err := New(Config{
+		Translation: &watson.Config{
+			Credentials:    watson.Credentials{Url: backend.URL, Username: "uuuu", Password: "pppp"},
+			LearningOptOut: true,
+		},
+		Authenticate: func(r *http.Request) (string, error) {
+			if r.Header.Get("Authorization") != "Bearer key" {
+				return "", errors.New("bad key")
+			}
+			return "tenant", nil
+		},
+		Quota:     NewRateQuota(2, time.Hour),
+		CacheSize: 10,
+		Audit: func(record AuditRecord) {
+			mu.Lock()
+			records = append(records, record)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Errorf("New() failed %#v\n", err)
+		return
+	}
+	gateway := httptest.NewServer(s)
+	defer gateway.Close()
+
+	post := func(key string) (int, string) {
+		req, _ := http.NewRequest("POST", gateway.URL+"/v1/translate", strings.NewReader(`{"text": "hello", "source": "en", "target": "es"}`))
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("gateway request failed %#v\n", err)
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	if code, _ := post("wrong"); code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated request: wanted status %d, got %d\n", http.StatusUnauthorized, code)
+	}
+	code, body := post("key")
+	if code != http.StatusOK || !strings.Contains(body, "hola") {
+		t.Errorf("translate: wanted status %d with translation, got %d %s\n", http.StatusOK, code, body)
+	}
+	if code, _ := post("key"); code != http.StatusOK {
+		t.Errorf("cached translate: wanted status %d, got %d\n", http.StatusOK, code)
+	}
+	if calls != 1 {
+		t.Errorf("backend called %d times, wanted 1 (second reply should be cached)\n", calls)
+	}
+	if code, _ := post("key"); code != http.StatusTooManyRequests {
+		t.Errorf("over quota: wanted status %d, got %d\n", http.StatusTooManyRequests, code)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 4 {
+		t.Errorf("wanted 4 audit records, got %d\n", len(records))
+		return
+	}
+	if records[1].Tenant != "tenant" || records[1].Cached || !records[2].Cached {
+		t.Errorf("unexpected audit records %#v\n", records)
+	}
+}
+
+func TestGatewayUpstreamErrors(t *testing.T) {
+	status := http.StatusUnauthorized
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code": 0, "error": "upstream"}`))
+	}))
+	defer backend.Close()
+
+	s, err := New(Config{
+		Translation:  &watson.Config{Credentials: watson.Credentials{Url: backend.URL, Username: "uuuu", Password: "pppp"}},
+		Authenticate: func(r *http.Request) (string, error) { return "tenant", nil },
+		Audit:        func(record AuditRecord) {},
+	})
+	if err != nil {
+		t.Errorf("New() failed %#v\n", err)
+		return
+	}
+	gateway := httptest.NewServer(s)
+	defer gateway.Close()
+
+	for _, test := range []struct{ upstream, want int }{
+		{http.StatusUnauthorized, http.StatusBadGateway},
+		{http.StatusForbidden, http.StatusBadGateway},
+		{http.StatusNotFound, http.StatusNotFound},
+		{http.StatusInternalServerError, http.StatusBadGateway},
+	} {
+		status = test.upstream
+		resp, err := http.Post(gateway.URL+"/v1/translate", "application/json", strings.NewReader(`{"text": "hello", "source": "en", "target": "es"}`))
+		if err != nil {
+			t.Errorf("gateway request failed %#v\n", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.want {
+			t.Errorf("upstream status %d: wanted %d, got %d\n", test.upstream, test.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestRateQuotaPrune(t *testing.T) {
+	now := time.Unix(0, 0)
+	q := NewRateQuota(1, time.Minute)
+	q.now = func() time.Time { return now }
+
+	for _, tenant := range []string{"a", "b", "c"} {
+		if !q.Allow(tenant) {
+			t.Errorf("first call of tenant %s refused\n", tenant)
+		}
+	}
+	if q.Allow("a") {
+		t.Errorf("second call within the window allowed\n")
+	}
+	now = now.Add(time.Minute)
+	if !q.Allow("a") {
+		t.Errorf("call in a new window refused\n")
+	}
+	if len(q.windows) != 1 {
+		t.Errorf("expired windows not evicted: %d windows left\n", len(q.windows))
+	}
+}
+
+func TestCacheBytes(t *testing.T) {
+	c := newCache(10, 10, 6, time.Hour)
+	c.put("a", reply{body: []byte("aaaa")})
+	c.put("big", reply{body: []byte("bbbbbbb")})
+	if _, ok := c.get("big"); ok {
+		t.Errorf("reply larger than maxReply was cached\n")
+	}
+	c.put("c", reply{body: []byte("cccc")})
+	c.put("d", reply{body: []byte("dddd")})
+	if _, ok := c.get("a"); ok || c.bytes != 8 {
+		t.Errorf("cache holds %d bytes, beyond maxBytes\n", c.bytes)
+	}
+	if r, ok := c.get("d"); !ok || string(r.body) != "dddd" {
+		t.Errorf("get() returned %#v, %v\n", r, ok)
+	}
+}
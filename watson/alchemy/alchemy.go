@@ -43,7 +43,7 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
@@ -51,6 +51,12 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return alchemy, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
+}
+
 // DetectAlchemyPath takes in a byte slice, typically encoding a string, and determine which type
 // of API to use amongst the 3 variants in AlchemyAPI: URL, HTML or text
 func detectAlchemyPath(data []byte) (key string, pathPrefix string, err error) {
@@ -19,6 +19,7 @@ package alchemy_data_news
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/liviosoares/go-watson-sdk/watson"
 	"github.com/liviosoares/go-watson-sdk/watson/alchemy"
@@ -37,6 +38,12 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return Client{alchemyClient: &client}, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	client := c.alchemyClient.WithHeaders(header)
+	return Client{alchemyClient: &client}
+}
+
 type Result map[string]interface{}
 
 // GetNews calls the AlchemyData News endpoint to retrieve recent news articles according to the provided query.
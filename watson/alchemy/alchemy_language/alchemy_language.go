@@ -18,6 +18,8 @@
 package alchemy_language
 
 import (
+	"net/http"
+
 	"github.com/liviosoares/go-watson-sdk/watson"
 	"github.com/liviosoares/go-watson-sdk/watson/alchemy"
 )
@@ -35,6 +37,12 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return Client{alchemyClient: &client}, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	client := c.alchemyClient.WithHeaders(header)
+	return Client{alchemyClient: &client}
+}
+
 type SentimentResponse struct {
 	alchemy.BaseResponse
 	DocSentiment DocSentiment `json:"docSentiment"`
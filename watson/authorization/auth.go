@@ -27,6 +27,12 @@ func GetToken(creds watson.Credentials) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return GetClientToken(serviceClient)
+}
+
+// GetClientToken is like GetToken, for the service of serviceClient. The token request is made with the
+// headers and in the dry-run mode of serviceClient.
+func GetClientToken(serviceClient *watson.Client) (string, error) {
 	u, err := url.Parse(serviceClient.Creds.Url)
 	if err != nil {
 		return "", err
@@ -0,0 +1,68 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authorization
+
+import (
+	"sync"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+// TokenCache caches the token of a service, so that a new token is not requested for every connection.
+// Tokens are valid for an hour; they are requested again once older than the TTL of the cache.
+// A TokenCache is safe for use by multiple goroutines.
+type TokenCache struct {
+	client *watson.Client
+	ttl    time.Duration
+	// fetch is replaced in tests
+	fetch func(*watson.Client) (string, error)
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenCache creates a cache of tokens for the service of client, whose headers and dry-run mode apply to
+// the token requests. A ttl of zero defaults to 50 minutes.
+func NewTokenCache(client *watson.Client, ttl time.Duration) *TokenCache {
+	if ttl <= 0 {
+		ttl = 50 * time.Minute
+	}
+	return &TokenCache{client: client, ttl: ttl, fetch: GetClientToken}
+}
+
+// Token returns the cached token, requesting a new one if it has expired
+func (c *TokenCache) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.token) > 0 && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	token, err := c.fetch(c.client)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expires = token, time.Now().Add(c.ttl)
+	return token, nil
+}
+
+// Invalidate drops the cached token, for example after it was rejected by the service
+func (c *TokenCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
@@ -0,0 +1,41 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authorization
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+func TestTokenCache(t *testing.T) {
+	fetches := 0
+	c := NewTokenCache(&watson.Client{}, 0)
+	c.fetch = func(*watson.Client) (string, error) {
+		fetches++
+		return "token" + strconv.Itoa(fetches), nil
+	}
+	for i := 0; i < 3; i++ {
+		if token, err := c.Token(); token != "token1" || err != nil {
+			t.Errorf("Token() returned %q, %#v\n", token, err)
+		}
+	}
+	c.Invalidate()
+	if token, _ := c.Token(); token != "token2" {
+		t.Errorf("Token() after Invalidate() returned %q\n", token)
+	}
+}
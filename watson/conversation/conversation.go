@@ -43,7 +43,7 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
@@ -51,6 +51,12 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return ci, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
+}
+
 type Intent struct {
 	Intent     string  `json:"intent,omitempty"`
 	Confidence float64 `json:"confidence,omitempty"`
@@ -0,0 +1,195 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrDryRun is returned by MakeRequest when the client is in dry-run mode and the request was
+// rendered as a curl command instead of being sent.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+const maskedSecret = "****"
+
+// secretHeaders and secretParams list header names and query/form parameters whose values are masked
+// in rendered curl commands.
+var secretHeaders = []string{"Authorization", "X-Watson-Authorization-Token"}
+var secretParams = []string{"apikey", "api_key", "password", "token", "watson-token"}
+
+// curlCommand renders req, whose body has already been read into body, as an equivalent curl command
+// line. Credentials, tokens and API keys are masked.
+func curlCommand(req *http.Request, username string, body []byte) string {
+	args := []string{"curl", "-X", req.Method}
+	if len(username) > 0 {
+		args = append(args, "-u", shellQuote(username+":"+maskedSecret))
+	}
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	isMultipart := strings.HasPrefix(mediaType, "multipart/") && len(params["boundary"]) > 0
+	for _, key := range keys {
+		if isMultipart && key == "Content-Type" {
+			// curl generates its own boundary when using -F
+			continue
+		}
+		if len(username) > 0 && key == "Authorization" {
+			// already covered by -u
+			continue
+		}
+		for _, v := range req.Header[key] {
+			if isSecret(secretHeaders, key) {
+				v = maskedSecret
+			}
+			args = append(args, "-H", shellQuote(key+": "+v))
+		}
+	}
+
+	switch {
+	case len(body) == 0:
+	case isMultipart:
+		args = append(args, curlFormArgs(body, params["boundary"])...)
+	case mediaType == "application/x-www-form-urlencoded":
+		if q, err := url.ParseQuery(string(body)); err == nil {
+			body = []byte(encodeMasked(q))
+		}
+		args = append(args, "--data-binary", shellQuote(string(body)))
+	case utf8.Valid(body):
+		args = append(args, "--data-binary", shellQuote(string(body)))
+	default:
+		// binary payloads (e.g. audio) are expected on stdin
+		args = append(args, "--data-binary", "@-")
+	}
+
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = encodeMasked(u.Query())
+	args = append(args, shellQuote(u.String()))
+	return strings.Join(args, " ")
+}
+
+// DryRunWebsocket writes the opening handshake of a websocket to u, with the headers of the client, as an
+// equivalent curl command to c.DryRun, and returns ErrDryRun. The websocket interfaces of the services,
+// which do not go through MakeRequest, call it instead of connecting when the client is in dry-run mode.
+func (c *Client) DryRunWebsocket(u *url.URL) error {
+	hu := *u
+	switch hu.Scheme {
+	case "ws":
+		hu.Scheme = "http"
+	case "wss":
+		hu.Scheme = "https"
+	}
+	req, err := http.NewRequest("GET", hu.String(), nil)
+	if err != nil {
+		return err
+	}
+	mergeHeader(req.Header, c.Headers)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if _, err := io.WriteString(c.DryRun, curlCommand(req, "", nil)+"\n"); err != nil {
+		return err
+	}
+	return ErrDryRun
+}
+
+// curlFormArgs converts a multipart/form-data body into curl -F/--form-string arguments. Parts carrying
+// a file name are referenced as local files of the same name. A malformed body is expected on stdin instead.
+func curlFormArgs(body []byte, boundary string) []string {
+	var args []string
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the parts rendered so far would be sent along with the raw body
+			return []string{"--data-binary", "@-"}
+		}
+		if len(part.FileName()) > 0 {
+			arg := part.FormName() + "=@" + part.FileName()
+			if ct := part.Header.Get("Content-Type"); len(ct) > 0 {
+				arg += ";type=" + ct
+			}
+			args = append(args, "-F", shellQuote(arg))
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil || !utf8.Valid(value) {
+			args = append(args, "-F", shellQuote(part.FormName()+"=@-"))
+			continue
+		}
+		args = append(args, "--form-string", shellQuote(part.FormName()+"="+string(value)))
+	}
+	return args
+}
+
+// encodeMasked is like q.Encode(), but with the values of secret parameters replaced by the mask, which is
+// not escaped so that it reads as such
+func encodeMasked(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for key := range q {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		for _, v := range q[key] {
+			if isSecret(secretParams, key) {
+				pairs = append(pairs, url.QueryEscape(key)+"="+maskedSecret)
+				break
+			}
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func isSecret(names []string, key string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	if len(s) > 0 && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
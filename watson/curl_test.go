@@ -0,0 +1,109 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watson
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var out bytes.Buffer
+	c, err := NewClientFromConfig(Config{
+		Credentials: Credentials{Url: "https://example.com/api", Username: "uuuu", Password: "pppp"},
+		DryRun:      &out,
+	})
+	if err != nil {
+		t.Errorf("NewClientFromConfig() failed %#v\n", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	w.WriteField("training_metadata", `{"language":"en"}`)
+	part, _ := w.CreateFormFile("file", "doc.pdf")
+	part.Write([]byte("%PDF"))
+	w.Close()
+	header := make(http.Header)
+	header.Set("Content-Type", w.FormDataContentType())
+
+	_, err = c.MakeRequest("POST", "/v1/classifiers?watson-token=secret&version=1", buf, header)
+	if err != ErrDryRun {
+		t.Errorf("MakeRequest() in dry-run mode returned %#v, wanted ErrDryRun\n", err)
+		return
+	}
+	cmd := out.String()
+	for _, want := range []string{
+		"curl -X POST -u 'uuuu:****'",
+		`--form-string 'training_metadata={"language":"en"}'`,
+		"-F 'file=@doc.pdf;type=application/octet-stream'",
+		"'https://example.com/api/v1/classifiers?version=1&watson-token=****'",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("dry-run output missing %q, got:\n%s\n", want, cmd)
+		}
+	}
+	for _, secret := range []string{"pppp", "secret", "multipart/form-data"} {
+		if strings.Contains(cmd, secret) {
+			t.Errorf("dry-run output contains %q, got:\n%s\n", secret, cmd)
+		}
+	}
+}
+
+func TestDryRunMalformedForm(t *testing.T) {
+	body := "--b\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\none\r\n--b\r\ngarbage"
+	args := strings.Join(curlFormArgs([]byte(body), "b"), " ")
+	if args != "--data-binary @-" {
+		t.Errorf("curlFormArgs() of a malformed body returned %s\n", args)
+	}
+}
+
+func TestDryRunWebsocket(t *testing.T) {
+	var out bytes.Buffer
+	c, _ := NewClientFromConfig(Config{
+		Credentials: Credentials{Url: "https://example.com/api", Username: "uuuu", Password: "pppp"},
+		Headers:     http.Header{"X-Correlation-Id": {"abc"}},
+		DryRun:      &out,
+	})
+	u, _ := url.Parse("wss://example.com/api/v1/recognize?watson-token=secret")
+	if err := c.DryRunWebsocket(u); err != ErrDryRun {
+		t.Errorf("DryRunWebsocket() returned %#v, wanted ErrDryRun\n", err)
+	}
+	cmd := out.String()
+	for _, want := range []string{"-H 'Upgrade: websocket'", "-H 'X-Correlation-Id: abc'", "'https://example.com/api/v1/recognize?watson-token=****'"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("dry-run output missing %q, got:\n%s\n", want, cmd)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":       "plain",
+		"":            "''",
+		"two words":   "'two words'",
+		"it's quoted": `'it'\''s quoted'`,
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, wanted %s\n", in, got, want)
+		}
+	}
+}
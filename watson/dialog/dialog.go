@@ -50,7 +50,7 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
@@ -58,6 +58,12 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return dialog, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
+}
+
 type dialogs struct {
 	Dialogs       []Dialog `json:"dialogs,omitempty"`
 	LanguagePacks []Dialog `json:"language_packs,omitempty"`
@@ -14,8 +14,12 @@
 // limitations under the License.
 
 // Package natural_language_classifier provides an interface to Watson Natural Language Classifier service.
+//
+// Types and simple methods are generated from swagger.json by watson-gen (see api_gen.go).
 package natural_language_classifier
 
+//go:generate go run ../../cmd/watson-gen -package natural_language_classifier -o api_gen.go swagger.json
+
 import (
 	"bytes"
 	"encoding/json"
@@ -23,7 +27,6 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
-	"net/url"
 
 	"github.com/liviosoares/go-watson-sdk/watson"
 )
@@ -48,7 +51,7 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
@@ -56,24 +59,13 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return nlc, nil
 }
 
-type Classifiers struct {
-	Classifiers []Classifier `json:"classifiers"`
-}
-
-type Classifier struct {
-	// User-supplied name for the classifier ,
-	Name string `json:"name,omitempty"`
-	// The language used for the classifier ,
-	Language string `json:"language,omitempty"`
-	// Link to the classifier
-	URL string `json:"url"`
-	// Unique identifier for this classifier
-	ClassifierId string `json:"classifier_id"`
-	// Date and time (UTC) the classifier was created
-	Created string `json:"created,omitempty"`
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
 }
 
-// Calls 'GET /v1/dialogs' to extract list of dialogs
+// Calls 'GET /v1/classifiers' to list classifiers
 func (c Client) ListClassifiers() ([]Classifier, error) {
 	body, err := c.watsonClient.MakeRequest("GET", c.version+"/classifiers", nil, nil)
 	if err != nil {
@@ -87,31 +79,6 @@ func (c Client) ListClassifiers() ([]Classifier, error) {
 	return cl.Classifiers, nil
 }
 
-// Used to specify metadata in classifier creation (see CreateClassifer())
-type ClassifierMetadata struct {
-	// User-supplied name for the classifier
-	Name string `json:"name"`
-	// The language used for the classifier
-	Language string `json:"language"`
-}
-
-type ClassifierStatus struct {
-	// User-supplied name for the classifier
-	Name string `json:"name,omitempty"`
-	// Link to the classifier
-	URL string `json:"url,omitempty"`
-	// The state of the classifier = ['Non Existent', 'Training', 'Failed', 'Available', 'Unavailable'],
-	Status string `json:"status,omitempty"`
-	// Unique identifier for this classifier ,
-	ClassifierId string `json:"classifier_id,omitempty"`
-	// Date and time (UTC) the classifier was created
-	Created string `json:"created,omitempty"`
-	// Additional detail about the status
-	StatusDescription string `json:"status_description,omitempty"`
-	// The language used for the classifier
-	Language string `json:"language,omitempty"`
-}
-
 // Calls 'POST /v1/classifiers' to create a classifier
 // The metadata identifies the language of the data, and an optional name to identify the classifier.
 // Training data in CSV format. Each text value must have at least one class. The data can include up to 15,000 records.
@@ -146,52 +113,3 @@ func (c Client) CreateClassifier(metadata ClassifierMetadata, training_csv io.Re
 	err = json.Unmarshal(b, &s)
 	return s, err
 }
-
-// Calls 'GET /v1/classifiers/{classifier_id}' to get information about a classifier
-func (c Client) GetClassifierStatus(classifier_id string) (ClassifierStatus, error) {
-	b, err := c.watsonClient.MakeRequest("GET", c.version+"/classifiers/"+classifier_id, nil, nil)
-	if err != nil {
-		return ClassifierStatus{}, err
-	}
-	var s ClassifierStatus
-	err = json.Unmarshal(b, &s)
-	return s, err
-}
-
-// Calls 'DELETE /v1/classifiers/{classifier_id}' to delete a classifier
-func (c Client) DeleteClassifier(classifier_id string) error {
-	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/classifiers/"+classifier_id, nil, nil)
-	return err
-}
-
-type Classification struct {
-	//  (string, optional): Unique identifier for this classifier ,
-	ClassifierId string `json:"classifier_id,omitempty"`
-	// Link to the classifier
-	URL string `json:"url,omitempty"`
-	// The submitted phrase
-	Text string `json:"text,omitempty"`
-	// The class with the highest confidence
-	TopClass string `json:"top_class,omitempty"`
-	// An array of up to ten class-confidence pairs sorted in descending order of confidence
-	Classes []Class `json:"classes,omitempty"`
-}
-
-type Class struct {
-	// A decimal percentage that represents the confidence that Watson has in this class. Higher values represent higher confidences.
-	Confidence float64 `json:"confidence,omitempty"`
-	// Class label
-	ClassName string `json:"class_name,omitempty"`
-}
-
-func (c Client) Classify(classifier_id string, text string) (Classification, error) {
-	q := url.Values{}
-	q.Set("text", text)
-	b, err := c.watsonClient.MakeRequest("GET", c.version+"/classifiers/"+classifier_id+"/classify?"+q.Encode(), nil, nil)
-	if err != nil {
-		return Classification{}, err
-	}
-	var class Classification
-	err = json.Unmarshal(b, &class)
-	return class, err
-}
@@ -16,6 +16,7 @@
 package watson
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -31,14 +32,29 @@ const goSdkVersion = "0.1.0"
 // It is used by service-specific clients to make requests and unmarshal replies and error codes.
 type Client struct {
 	Creds Credentials
+	// Headers are sent with every request issued by the client. Headers passed to
+	// MakeRequest take precedence over these, on a key by key basis.
+	Headers http.Header
+	// DryRun, if non-nil, puts the client in dry-run mode: requests are not sent, but written to DryRun
+	// as equivalent curl commands (with secrets masked), and MakeRequest returns ErrDryRun.
+	DryRun io.Writer
 }
 
+// LearningOptOutHeader is the request header used to opt out of Watson request logging.
+const LearningOptOutHeader = "X-Watson-Learning-Opt-Out"
+
 // Config contains versioning and credential information to a specific Watson service.
 type Config struct {
 	// Version of API to use; defaults to "v1"
 	Version string
 	// Credentials to use. If empty, VCAP_SERVICES environment variable will be used
 	Credentials Credentials
+	// Headers to send with every request made to the service (e.g. correlation IDs)
+	Headers http.Header
+	// LearningOptOut, if set, sends the X-Watson-Learning-Opt-Out header with every request
+	LearningOptOut bool
+	// DryRun, if non-nil, renders requests as curl commands written to DryRun instead of sending them
+	DryRun io.Writer
 }
 
 // Credentials contains information necessary to connect to a specific Watson service.
@@ -71,6 +87,69 @@ func NewClient(creds Credentials) (*Client, error) {
 	return &Client{Creds: creds}, nil
 }
 
+// NewClientFromConfig creates a generic Watson client object like NewClient, using the credentials in cfg.
+// Headers configured in cfg are sent with every request made by the returned client.
+func NewClientFromConfig(cfg Config) (*Client, error) {
+	c, err := NewClient(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	c.Headers = copyHeader(cfg.Headers)
+	if cfg.LearningOptOut {
+		c.Headers.Set(LearningOptOutHeader, "true")
+	}
+	c.DryRun = cfg.DryRun
+	return c, nil
+}
+
+// WithHeaders returns a copy of the client which sends header with every request, in addition to the
+// headers already configured in the client. Values in header replace existing values for the same key.
+// The WithHeaders methods of the service clients return a copy of the service client in the same way.
+// Client headers are also sent when opening websockets (for example, by speech_to_text.Client.NewStream);
+// to override headers for a single request instead, see WithRequestHeaders.
+func (c *Client) WithHeaders(header http.Header) *Client {
+	n := new(Client)
+	*n = *c
+	n.Headers = copyHeader(c.Headers)
+	mergeHeader(n.Headers, header)
+	return n
+}
+
+// copyHeader returns a deep copy of h; the result is never nil.
+func copyHeader(h http.Header) http.Header {
+	n := make(http.Header, len(h))
+	mergeHeader(n, h)
+	return n
+}
+
+// mergeHeader copies all values of src into dst, replacing any values dst held for the same keys.
+func mergeHeader(dst, src http.Header) {
+	for key, values := range src {
+		dst.Del(key)
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
+
+type requestHeadersKey struct{}
+
+// WithRequestHeaders returns a copy of ctx carrying header, which is sent with the requests made with the
+// returned context, such as those of MakeRequestContext, DoRequest and the service methods taking a context.
+// Values in header replace the values of the client headers for the same keys; headers already carried by
+// ctx are kept, unless replaced as well.
+func WithRequestHeaders(ctx context.Context, header http.Header) context.Context {
+	h := copyHeader(RequestHeaders(ctx))
+	mergeHeader(h, header)
+	return context.WithValue(ctx, requestHeadersKey{}, h)
+}
+
+// RequestHeaders returns the headers carried by ctx, set by WithRequestHeaders; the result must not be modified.
+func RequestHeaders(ctx context.Context) http.Header {
+	h, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return h
+}
+
 // WatsonError stores error information from a Watson API endpoint
 type WatsonError struct {
 	Code    int
@@ -113,57 +192,96 @@ func (ae *alternativeError1) Error() string {
 }
 
 // MakeRequest issues an HTTP request to one of the Watson API endpoints. Authentication information from the Client
-// object is used.
+// object is used. The client's default headers are sent along with header, whose values (all of them, for
+// multi-valued keys) take precedence; with MakeRequestContext and DoRequest, the headers carried by the context
+// (see WithRequestHeaders) take precedence over the client's, but not over header.
 // If the endpoint replies with a non-20x reply, an error of WatsonError type is returned, otherwise
 // the body of the reply is returned.
 func (c *Client) MakeRequest(method string, path string, body io.Reader, header http.Header) ([]byte, error) {
+	return c.MakeRequestContext(context.Background(), method, path, body, header)
+}
+
+// MakeRequestContext is like MakeRequest, but the request is canceled when ctx is done.
+// A body whose length is not known in advance (e.g. an *os.File or a pipe) is sent using chunked
+// transfer encoding.
+func (c *Client) MakeRequestContext(ctx context.Context, method string, path string, body io.Reader, header http.Header) ([]byte, error) {
+	resp, err := c.DoRequest(ctx, method, path, body, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// DoRequest is like MakeRequestContext, but returns the HTTP response of a 20x reply, so that its headers
+// are available and its body can be streamed; the caller must close the body. Non-20x replies are
+// returned as an error of WatsonError type, as with MakeRequest.
+func (c *Client) DoRequest(ctx context.Context, method string, path string, body io.Reader, header http.Header) (*http.Response, error) {
 	req, err := http.NewRequest(method, c.Creds.Url+path, body)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.SetBasicAuth(c.Creds.Username, c.Creds.Password)
-	for key := range header {
-		req.Header.Set(key, header[key][0])
-	}
+	mergeHeader(req.Header, c.Headers)
+	mergeHeader(req.Header, RequestHeaders(ctx))
+	mergeHeader(req.Header, header)
 	req.Header.Set("User-Agent", "watson-developer-cloud-go-"+goSdkVersion)
+	if c.DryRun != nil {
+		var b []byte
+		if body != nil {
+			b, err = ioutil.ReadAll(body)
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = io.WriteString(c.DryRun, curlCommand(req, c.Creds.Username, b)+"\n")
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrDryRun
+	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
 
 	defer resp.Body.Close()
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	return nil, responseError(resp.StatusCode, b)
+}
 
-	if resp.StatusCode < 300 {
-		return b, nil
-	}
-
+// responseError returns the error described by the body b of a non-20x reply
+func responseError(status int, b []byte) error {
 	if len(b) == 0 {
-		return nil, &WatsonError{Code: resp.StatusCode, Message: ""}
+		return &WatsonError{Code: status, Message: ""}
 	}
 
 	var e watsonError
-	err = json.Unmarshal(b, &e)
+	err := json.Unmarshal(b, &e)
 	if err == nil && e.Code != 0 && len(e.Message) > 0 {
-		return nil, &WatsonError{Code: e.Code, Message: e.Message}
+		return &WatsonError{Code: e.Code, Message: e.Message}
 	}
 	var ae alternativeError
 	err = json.Unmarshal(b, &ae)
 	if err == nil && ae.Code != 0 && len(ae.Message) > 0 {
-		return nil, &WatsonError{Code: ae.Code, Message: ae.Message}
+		return &WatsonError{Code: ae.Code, Message: ae.Message}
 	}
 	var ae1 alternativeError1
 	err = json.Unmarshal(b, &ae1)
 	if err == nil && ae1.Code != 0 && ae1.Message != nil && len(*ae1.Message) > 0 {
-		return nil, &WatsonError{Code: ae1.Code, Message: *ae1.Message}
+		return &WatsonError{Code: ae1.Code, Message: *ae1.Message}
 	}
 
 	if utf8.ValidString(string(b)) {
-		return nil, &WatsonError{Code: resp.StatusCode, Message: string(b)}
+		return &WatsonError{Code: status, Message: string(b)}
 	}
 
-	return nil, errors.New("received non-20x status code and body contained invalid error JSON")
+	return errors.New("received non-20x status code and body contained invalid error JSON")
 }
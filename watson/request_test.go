@@ -0,0 +1,104 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watson
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMakeRequestHeaders(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	cfg := Config{
+		Credentials: Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"},
+		Headers: http.Header{
+			"X-Correlation-Id": {"default"},
+			"X-Multi":          {"a", "b"},
+		},
+		LearningOptOut: true,
+	}
+	c, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Errorf("NewClientFromConfig() failed %#v\n", err)
+		return
+	}
+	c = c.WithHeaders(http.Header{"X-Correlation-Id": {"per-client"}})
+
+	header := make(http.Header)
+	header.Add("X-Multi", "c")
+	header.Add("X-Multi", "d")
+	_, err = c.MakeRequest("GET", "/", nil, header)
+	if err != nil {
+		t.Errorf("MakeRequest() failed %#v\n", err)
+		return
+	}
+	want := map[string][]string{
+		LearningOptOutHeader: {"true"},
+		"X-Correlation-Id":   {"per-client"},
+		"X-Multi":            {"c", "d"},
+	}
+	for key, values := range want {
+		if !reflect.DeepEqual(got[http.CanonicalHeaderKey(key)], values) {
+			t.Errorf("MakeRequest() sent header %s = %#v, wanted %#v\n", key, got[http.CanonicalHeaderKey(key)], values)
+		}
+	}
+	if cfg.Headers.Get("X-Correlation-Id") != "default" {
+		t.Errorf("WithHeaders() modified configured headers, got %#v\n", cfg.Headers)
+	}
+}
+
+func TestWithRequestHeaders(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	c, err := NewClientFromConfig(Config{
+		Credentials: Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"},
+		Headers:     http.Header{"X-Correlation-Id": {"default"}, "X-Tenant": {"t1"}},
+	})
+	if err != nil {
+		t.Errorf("NewClientFromConfig() failed %#v\n", err)
+		return
+	}
+	ctx := WithRequestHeaders(context.Background(), http.Header{"X-Correlation-Id": {"call-1"}, "Accept": {"text/plain"}})
+	ctx = WithRequestHeaders(ctx, http.Header{"X-Extra": {"e"}})
+	_, err = c.MakeRequestContext(ctx, "GET", "/", nil, http.Header{"Accept": {"application/json"}})
+	if err != nil {
+		t.Errorf("MakeRequestContext() failed %#v\n", err)
+		return
+	}
+	want := map[string]string{"X-Correlation-Id": "call-1", "X-Tenant": "t1", "X-Extra": "e", "Accept": "application/json"}
+	for key, value := range want {
+		if got.Get(key) != value {
+			t.Errorf("MakeRequestContext() sent header %s = %#v, wanted %#v\n", key, got.Get(key), value)
+		}
+	}
+	if c.Headers.Get("X-Correlation-Id") != "default" {
+		t.Errorf("WithRequestHeaders() modified the client headers %#v\n", c.Headers)
+	}
+}
@@ -14,8 +14,12 @@
 // limitations under the License.
 
 // Package retrieve_and_rank provides an interface to Watson Retrieve and Rank service.
+//
+// Types and simple methods are generated from swagger.json by watson-gen (see api_gen.go).
 package retrieve_and_rank
 
+//go:generate go run ../../cmd/watson-gen -package retrieve_and_rank -o api_gen.go swagger.json
+
 import (
 	"bytes"
 	"encoding/json"
@@ -45,7 +49,7 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
@@ -53,30 +57,10 @@ func NewClient(cfg watson.Config) (Client, error) {
 	return ci, nil
 }
 
-type ClusterList struct {
-	Clusters []Cluster `json:"clusters"`
-}
-
-type Cluster struct {
-	// Unique identifier for this cluster
-	Id string `json:"solr_cluster_id,omitempty"`
-	// Name that identifies the cluster
-	Name string `json:"cluster_name,omitempty"`
-	// Size of the cluster to create
-	Size string `json:"cluster_size,omitempty"`
-	// The state of the cluster = ['NOT_AVAILABLE', 'READY']}
-	Status string `json:"solr_cluster_status,omitempty"`
-}
-
-// Calls 'GET /v1/solr_clusters' to get a list Solr clusters
-func (c Client) ListClusters() (ClusterList, error) {
-	body, err := c.watsonClient.MakeRequest("GET", c.version+"/solr_clusters", nil, nil)
-	if err != nil {
-		return ClusterList{}, err
-	}
-	var response ClusterList
-	err = json.Unmarshal(body, &response)
-	return response, err
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
 }
 
 // Calls 'POST /v1/solr_clusters' to create Solr cluster
@@ -108,44 +92,6 @@ func (c Client) CreateCluster(name string, size int) (Cluster, error) {
 	return response, err
 }
 
-// Calls 'DELETE /v1/solr_clusters/{solr_cluster_id}' to delete a Solr cluster
-func (c Client) DeleteCluster(id string) error {
-	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/solr_clusters/"+id, nil, nil)
-	return err
-}
-
-// Calls 'GET /v1/solr_clusters/{solr_cluster_id}' to retrieve information about a Solr cluster
-func (c Client) GetCluster(id string) (Cluster, error) {
-	body, err := c.watsonClient.MakeRequest("GET", c.version+"/solr_clusters/"+id, nil, nil)
-	if err != nil {
-		return Cluster{}, err
-	}
-	var response Cluster
-	err = json.Unmarshal(body, &response)
-	return response, err
-}
-
-// type Configs struct {
-// 	Configs []struct {
-//		ConfigName string `json:"config_name"`
-//	} `json:"solr_configs"`
-// }
-
-type Configs struct {
-	Configs []string `json:"solr_configs"`
-}
-
-// Calls 'GET /v1/solr_clusters/{solr_cluster_id}/config' to list Solr configurations
-func (c Client) ListConfigs(id string) (Configs, error) {
-	body, err := c.watsonClient.MakeRequest("GET", c.version+"/solr_clusters/"+id+"/config", nil, nil)
-	if err != nil {
-		return Configs{}, err
-	}
-	var response Configs
-	err = json.Unmarshal(body, &response)
-	return response, err
-}
-
 // Calls 'POST /v1/solr_clusters/{solr_cluster_id}/config/{config_name}' to upload Solr configuration
 func (c Client) UploadConfig(solr_id string, config_name string, zipReader io.Reader) error {
 	headers := make(http.Header)
@@ -155,12 +101,6 @@ func (c Client) UploadConfig(solr_id string, config_name string, zipReader io.Re
 	return err
 }
 
-// Calls 'DELETE /v1/solr_clusters/{solr_cluster_id}/config/{config_name}' to delete Solr configuration
-func (c Client) DeleteConfig(solr_id string, config_name string) error {
-	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/solr_clusters/"+solr_id+"/config/"+config_name, nil, nil)
-	return err
-}
-
 // Calls 'GET /v1/solr_clusters/{solr_cluster_id}/config/{config_name}' to get Solr configuration
 func (c Client) GetConfig(solr_id string, config_name string) ([]byte, error) {
 	return c.watsonClient.MakeRequest("GET", c.version+"/solr_clusters/"+solr_id+"/config/"+config_name, nil, nil)
@@ -228,37 +168,6 @@ func (c Client) Search(solr_id string, collection_name string, query string, opt
 	return c.watsonClient.MakeRequest("GET", c.version+"/solr_clusters/"+solr_id+"/solr/"+collection_name+"/select?"+q.Encode(), nil, nil)
 }
 
-type RankerList struct {
-	// InfoPayload], optional): The rankers available to the user. Returns an empty array if no rankers are available.
-	Rankers []Ranker `json:"rankers"`
-}
-
-type Ranker struct {
-	// Unique identifier for this ranker ,
-	RankerId string `json:"ranker_id,omitempty"`
-	// Link to the ranker ,
-	Url string `json:"url,omitempty"`
-	// User-supplied name for the ranker ,
-	Name string `json:"name,omitempty"`
-	// Date and time (UTC) the ranker was created
-	Created string `json:"created,omitempty"`
-	// The state of the ranker = ['Non Existent', 'Training', 'Failed', 'Available', 'Unavailable']
-	Status string `json:"status,omitempty`
-	// Additional detail about the status
-	StatusDescription string `json:"status_description,omitempty"`
-}
-
-// Calls 'GET /v1/rankers' to  list rankers
-func (c Client) ListRankers() (RankerList, error) {
-	body, err := c.watsonClient.MakeRequest("GET", c.version+"/rankers", nil, nil)
-	if err != nil {
-		return RankerList{}, err
-	}
-	var response RankerList
-	err = json.Unmarshal(body, &response)
-	return response, err
-}
-
 // Calls 'POST /v1/rankers' to create a ranker
 func (c Client) CreateRanker(name string, trainingData io.Reader) (Ranker, error) {
 	buf := &bytes.Buffer{}
@@ -295,45 +204,6 @@ func (c Client) CreateRanker(name string, trainingData io.Reader) (Ranker, error
 	return response, err
 }
 
-// Calls 'GET /v1/rankers/{ranker_id}' to get information about a ranker
-func (c Client) GetRanker(ranker_id string) (Ranker, error) {
-	body, err := c.watsonClient.MakeRequest("GET", c.version+"/rankers/"+ranker_id, nil, nil)
-	if err != nil {
-		return Ranker{}, err
-	}
-	var response Ranker
-	err = json.Unmarshal(body, &response)
-	return response, err
-}
-
-// Calls 'DELETE /v1/rankers/{ranker_id}' to delete ranker
-func (c Client) DeleteRanker(ranker_id string) error {
-	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/rankers/"+ranker_id, nil, nil)
-	return err
-}
-
-type RankerOutput struct {
-	//  Unique identifier for this ranker
-	RankerId string `json:"ranker_id,omitempty"`
-	// Name of this ranker
-	Name string `json:"name"`
-	// Link to the ranker
-	Url string `json:"url,omitempty"`
-	// The class with the highest confidence ,
-	TopAnswer string `json:"top_answer,omitempty"`
-	// An array of up to ten class-confidence pairs sorted in descending order of confidence
-	Answers []Answer `json:"answers,omitempty"`
-}
-
-type Answer struct {
-	// Answer label ,
-	AnswerId string `json:"answer_id,omitempty"`
-	// A decimal percentage that represents the confidence that Watson has in this class. Higher values represent higher confidences. ,
-	Score float64 `json:"score,omitempty"`
-	// A decimal percentage that represents the confidence that Watson has in this class. Higher values represent higher confidences.
-	Confidence float64 `json:"confidence,omitempty"`
-}
-
 // Calls 'POST /v1/rankers/{ranker_id}/rank' to rank a series of search queries
 // Returns the top answer and a list of ranked answers with their ranked scores and confidence values.
 // Use this method to return answers when you train the ranker with custom features. However, in most cases, you can use the Search and rank method.
@@ -0,0 +1,166 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// wavFile returns a WAV file of 16-bit PCM samples
+func wavFile(rate int, channels int, samples []int16) []byte {
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, samples)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+data.Len()))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, uint16(channels)})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(rate), uint32(rate * channels * 2)})
+	binary.Write(&buf, binary.LittleEndian, []uint16{uint16(channels * 2), 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	flac := append([]byte("fLaC\x00\x00\x00\x22"), make([]byte, 34)...)
+	// 44100 Hz, 2 channels, 16 bits per sample
+	copy(flac[8+10:], []byte{0x0a, 0xc4, 0x42, 0xf0})
+	ogg := append([]byte("OggS"), make([]byte, 22)...)
+	ogg = append(ogg, 1, 19)
+	ogg = append(ogg, []byte("OpusHead\x01\x01\x38\x01\x80\xbb\x00\x00\x00\x00\x00")...)
+
+	tests := []struct {
+		data []byte
+		want Format
+		ct   string
+	}{
+		{wavFile(8000, 1, []int16{1, 2}), Format{Encoding: WAV, SampleRate: 8000, Channels: 1, BitsPerSample: 16}, "audio/wav"},
+		{flac, Format{Encoding: FLAC, SampleRate: 44100, Channels: 2, BitsPerSample: 16}, "audio/flac"},
+		{ogg, Format{Encoding: OggOpus, SampleRate: 48000, Channels: 1}, "audio/ogg;codecs=opus"},
+	}
+	for _, test := range tests {
+		f, r, err := Sniff(bytes.NewReader(test.data))
+		if err != nil {
+			t.Errorf("Sniff() failed %#v\n", err)
+			continue
+		}
+		if f != test.want || f.ContentType() != test.ct {
+			t.Errorf("Sniff() returned %#v (%s), wanted %#v\n", f, f.ContentType(), test.want)
+		}
+		if all, _ := ioutil.ReadAll(r); !bytes.Equal(all, test.data) {
+			t.Errorf("Sniff() reader returned %d bytes, wanted %d\n", len(all), len(test.data))
+		}
+	}
+	if _, _, err := Sniff(bytes.NewReader([]byte{1, 2, 3, 4})); err != ErrUnknownFormat {
+		t.Errorf("Sniff() of raw audio returned %#v\n", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	f, r, err := WAVData(bytes.NewReader(wavFile(32000, 2, []int16{100, 300, 100, 300, 300, 100, 300, 100})))
+	if err != nil {
+		t.Errorf("WAVData() failed %#v\n", err)
+		return
+	}
+	to, r, err := Convert(r, f, 16000, 1)
+	if err != nil {
+		t.Errorf("Convert() failed %#v\n", err)
+		return
+	}
+	if to.ContentType() != "audio/l16; rate=16000; endianness=little-endian" {
+		t.Errorf("Convert() returned format %s\n", to.ContentType())
+	}
+	data, _ := ioutil.ReadAll(r)
+	samples := make([]int16, len(data)/2)
+	binary.Read(bytes.NewReader(data), binary.LittleEndian, samples)
+	if want := []int16{200, 200}; !reflect.DeepEqual(samples, want) {
+		t.Errorf("Convert() returned samples %v, wanted %v\n", samples, want)
+	}
+
+	// upsampling interpolates between frames
+	_, r, _ = Convert(bytes.NewReader([]byte{0, 0, 100, 0}), RawPCM(8000, 1), 16000, 1)
+	data, _ = ioutil.ReadAll(r)
+	samples = make([]int16, len(data)/2)
+	binary.Read(bytes.NewReader(data), binary.LittleEndian, samples)
+	if want := []int16{0, 50, 100, 100}; !reflect.DeepEqual(samples, want) {
+		t.Errorf("Convert() returned samples %v, wanted %v\n", samples, want)
+	}
+}
+
+// TestConvertAliasing checks that downsampling keeps tones below the new Nyquist rate and filters out those
+// above it, which would otherwise alias into the speech band
+func TestConvertAliasing(t *testing.T) {
+	rms := func(freq float64) float64 {
+		tone := make([]int16, 48000)
+		for i := range tone {
+			tone[i] = int16(10000 * math.Sin(2*math.Pi*freq*float64(i)/48000))
+		}
+		var b bytes.Buffer
+		binary.Write(&b, binary.LittleEndian, tone)
+		_, r, _ := Convert(&b, RawPCM(48000, 1), 16000, 1)
+		data, _ := ioutil.ReadAll(r)
+		samples := make([]int16, len(data)/2)
+		binary.Read(bytes.NewReader(data), binary.LittleEndian, samples)
+		sum := 0.0
+		// skip the edges, where the filter reaches past the audio
+		for _, s := range samples[100 : len(samples)-100] {
+			sum += float64(s) * float64(s)
+		}
+		return math.Sqrt(sum / float64(len(samples)-200))
+	}
+	full := 10000 / math.Sqrt2
+	if v := rms(1000); v < 0.95*full || v > 1.05*full {
+		t.Errorf("1kHz tone downsampled to RMS %.0f, wanted about %.0f\n", v, full)
+	}
+	if v := rms(12000); v > 0.01*full {
+		t.Errorf("12kHz tone downsampled to RMS %.0f, wanted it filtered out\n", v)
+	}
+}
+
+func TestPacer(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewPacer(&buf, RawPCM(16000, 1), 2)
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+	p.now = func() time.Time { return clock }
+	p.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+	// one second of audio, at twice real time
+	n, err := p.Write(make([]byte, 32000))
+	if n != 32000 || err != nil || buf.Len() != 32000 {
+		t.Errorf("Write() returned %d, %#v\n", n, err)
+	}
+	// the last of 10 chunks is written after 9 * 100ms / 2
+	if slept != 450*time.Millisecond {
+		t.Errorf("Write() slept %s, wanted 450ms\n", slept)
+	}
+
+	// chunks hold whole stereo frames
+	if p := NewPacer(&buf, RawPCM(11025, 2), 1); p.chunk%4 != 0 {
+		t.Errorf("NewPacer() for stereo audio uses %d byte chunks\n", p.chunk)
+	}
+}
@@ -0,0 +1,206 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audio provides helpers to prepare audio for the Watson Speech to Text service: detecting the
+// format of audio files, converting 16-bit PCM audio to supported rates, and pacing audio into streams.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Encodings of audio Formats
+const (
+	WAV     = "wav"
+	FLAC    = "flac"
+	OggOpus = "ogg-opus"
+	L16     = "l16"
+)
+
+// maxSniff is the size of the header read to detect formats
+const maxSniff = 4096
+
+var ErrUnknownFormat = errors.New("unknown audio format")
+
+// Format describes audio data
+type Format struct {
+	// One of WAV, FLAC, OggOpus or L16 (raw 16-bit PCM samples)
+	Encoding string
+	// Samples per second, per channel
+	SampleRate int
+	Channels   int
+	// Bits per sample; always 16 for L16
+	BitsPerSample int
+	// Byte order of L16 samples; little-endian unless set
+	BigEndian bool
+}
+
+// RawPCM returns the format of raw 16-bit little-endian PCM audio, which has no header to detect
+func RawPCM(rate int, channels int) Format {
+	return Format{Encoding: L16, SampleRate: rate, Channels: channels, BitsPerSample: 16}
+}
+
+// ContentType returns the content type to use for audio of format f in a recognition request
+func (f Format) ContentType() string {
+	switch f.Encoding {
+	case WAV:
+		return "audio/wav"
+	case FLAC:
+		return "audio/flac"
+	case OggOpus:
+		return "audio/ogg;codecs=opus"
+	case L16:
+		ct := "audio/l16; rate=" + strconv.Itoa(f.SampleRate)
+		if f.Channels > 1 {
+			ct += "; channels=" + strconv.Itoa(f.Channels)
+		}
+		if f.BigEndian {
+			return ct + "; endianness=big-endian"
+		}
+		return ct + "; endianness=little-endian"
+	}
+	return ""
+}
+
+// BytesPerSecond returns the data rate of uncompressed (L16 or WAV PCM) audio, or 0 for compressed audio
+func (f Format) BytesPerSecond() int {
+	if f.Encoding != L16 && f.Encoding != WAV {
+		return 0
+	}
+	return f.SampleRate * f.Channels * f.BitsPerSample / 8
+}
+
+// Sniff detects the format of the audio read from r, from the headers of WAV, FLAC and Ogg Opus data.
+// It returns a reader yielding the complete audio, headers included. Raw PCM audio cannot be detected;
+// ErrUnknownFormat is returned instead (see RawPCM).
+func Sniff(r io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReaderSize(r, maxSniff)
+	header, err := br.Peek(maxSniff)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return Format{}, br, err
+	}
+	var f Format
+	switch {
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		f, _, err = parseWAV(header)
+	case len(header) >= 4 && string(header[0:4]) == "fLaC":
+		f, err = parseFLAC(header)
+	case len(header) >= 4 && string(header[0:4]) == "OggS":
+		f, err = parseOggOpus(header)
+	default:
+		err = ErrUnknownFormat
+	}
+	return f, br, err
+}
+
+// WAVData reads the header of a WAV file containing 16-bit PCM audio, and returns the L16 format of its samples
+// and a reader of the samples, for use with Convert().
+func WAVData(r io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReaderSize(r, maxSniff)
+	header, err := br.Peek(maxSniff)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return Format{}, nil, err
+	}
+	if len(header) < 12 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return Format{}, nil, ErrUnknownFormat
+	}
+	f, offset, err := parseWAV(header)
+	if err != nil {
+		return Format{}, nil, err
+	}
+	if f.BitsPerSample != 16 {
+		return Format{}, nil, errors.New("unsupported WAV sample size " + strconv.Itoa(f.BitsPerSample))
+	}
+	if offset < 0 {
+		return Format{}, nil, errors.New("WAV data chunk not found in header")
+	}
+	br.Discard(offset)
+	f.Encoding = L16
+	return f, br, nil
+}
+
+// parseWAV parses the chunks of a WAV header, returning the offset of the samples in the data chunk, or -1
+// if not within header
+func parseWAV(header []byte) (Format, int, error) {
+	f := Format{Encoding: WAV}
+	found := false
+	for p := 12; p+8 <= len(header); {
+		id := string(header[p : p+4])
+		size := int(binary.LittleEndian.Uint32(header[p+4 : p+8]))
+		switch id {
+		case "fmt ":
+			if size < 16 || p+8+16 > len(header) {
+				return f, -1, errors.New("invalid WAV fmt chunk")
+			}
+			fmtChunk := header[p+8:]
+			if tag := binary.LittleEndian.Uint16(fmtChunk[0:2]); tag != 1 && tag != 0xfffe {
+				return f, -1, errors.New("unsupported WAV encoding " + strconv.Itoa(int(tag)))
+			}
+			f.Channels = int(binary.LittleEndian.Uint16(fmtChunk[2:4]))
+			f.SampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:8]))
+			f.BitsPerSample = int(binary.LittleEndian.Uint16(fmtChunk[14:16]))
+			found = true
+		case "data":
+			if !found {
+				return f, -1, errors.New("WAV data chunk before fmt chunk")
+			}
+			return f, p + 8, nil
+		}
+		// chunks are padded to an even size
+		p += 8 + size + size%2
+	}
+	if !found {
+		return f, -1, errors.New("WAV fmt chunk not found in header")
+	}
+	return f, -1, nil
+}
+
+// parseFLAC parses the STREAMINFO metadata block, which is always first
+func parseFLAC(header []byte) (Format, error) {
+	// "fLaC", 4 bytes of block header, then min/max block and frame sizes
+	if len(header) < 8+18 || header[4]&0x7f != 0 {
+		return Format{}, errors.New("invalid FLAC header")
+	}
+	info := header[8+10:]
+	// 20 bits of sample rate, 3 bits of channels - 1, 5 bits of bits per sample - 1
+	return Format{
+		Encoding:      FLAC,
+		SampleRate:    int(info[0])<<12 | int(info[1])<<4 | int(info[2])>>4,
+		Channels:      int(info[2]>>1&0x7) + 1,
+		BitsPerSample: int(info[2]&0x1)<<4 | int(info[3]>>4) + 1,
+	}, nil
+}
+
+// parseOggOpus parses the identification header in the first page of an Ogg Opus stream
+func parseOggOpus(header []byte) (Format, error) {
+	if len(header) < 27 || len(header) < 27+int(header[26]) {
+		return Format{}, errors.New("invalid Ogg header")
+	}
+	// the page header is followed by a segment table of header[26] entries
+	packet := header[27+int(header[26]):]
+	if len(packet) < 19 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+		return Format{}, ErrUnknownFormat
+	}
+	return Format{
+		Encoding:   OggOpus,
+		SampleRate: int(binary.LittleEndian.Uint32(packet[12:16])),
+		Channels:   int(packet[9]),
+	}, nil
+}
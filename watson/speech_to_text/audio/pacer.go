@@ -0,0 +1,87 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"io"
+	"time"
+)
+
+// Pacer is an io.Writer which paces the audio written to an underlying writer, such as a recognition
+// session, as if it was captured live: audio is written in chunks of 100ms, at most speed times faster
+// than real time.
+type Pacer struct {
+	w              io.Writer
+	bytesPerSecond int
+	speed          float64
+	chunk          int
+
+	start   time.Time
+	written int64
+	// now and sleep are replaced in tests
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewPacer creates a pacer writing to w audio of format f at speed times real time. A speed of 1 writes in
+// real time, 2 twice as fast; a speed of 0 or less, or compressed audio (see Format.BytesPerSecond()), is
+// written in chunks without pacing.
+func NewPacer(w io.Writer, f Format, speed float64) *Pacer {
+	bytesPerSecond := f.BytesPerSecond()
+	chunk := bytesPerSecond / 10
+	// keep chunks aligned with whole frames, i.e. a sample of every channel
+	if frame := f.Channels * f.BitsPerSample / 8; frame > 0 {
+		chunk -= chunk % frame
+	}
+	if chunk <= 0 {
+		chunk = 4096
+	}
+	return &Pacer{w: w, bytesPerSecond: bytesPerSecond, speed: speed, chunk: chunk, now: time.Now, sleep: time.Sleep}
+}
+
+// Write writes b in chunks, waiting before each chunk until it is due
+func (p *Pacer) Write(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		size := p.chunk
+		if size > len(b) {
+			size = len(b)
+		}
+		p.wait()
+		m, err := p.w.Write(b[:size])
+		n += m
+		p.written += int64(m)
+		if err != nil {
+			return n, err
+		}
+		b = b[size:]
+	}
+	return n, nil
+}
+
+// wait sleeps until the audio written so far has been played at the pacer's speed
+func (p *Pacer) wait() {
+	if p.start.IsZero() {
+		p.start = p.now()
+	}
+	if p.speed <= 0 || p.bytesPerSecond <= 0 {
+		return
+	}
+	due := time.Duration(float64(p.written) / float64(p.bytesPerSecond) / p.speed * float64(time.Second))
+	if elapsed := p.now().Sub(p.start); elapsed < due {
+		p.sleep(due - elapsed)
+	}
+}
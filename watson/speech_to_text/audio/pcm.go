@@ -0,0 +1,209 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Convert returns a reader of the 16-bit PCM audio read from r, downmixed and resampled to the given sample
+// rate and number of channels; the Watson Speech to Text broadband models expect 16000 Hz and narrowband
+// models 8000 Hz audio. from must be an L16 format, as returned by WAVData() or RawPCM(); channels must be
+// 1 or from.Channels. The samples are resampled by linear interpolation, after a low-pass filter when
+// downsampling so that frequencies above the new Nyquist rate do not alias, and returned in little-endian
+// order, as described by the returned Format.
+func Convert(r io.Reader, from Format, rate int, channels int) (Format, io.Reader, error) {
+	if from.Encoding != L16 || from.BitsPerSample != 16 {
+		return Format{}, nil, errors.New("only 16-bit PCM audio can be converted")
+	}
+	if from.SampleRate <= 0 || from.Channels <= 0 || rate <= 0 {
+		return Format{}, nil, errors.New("invalid sample rate or channels")
+	}
+	if channels != 1 && channels != from.Channels {
+		return Format{}, nil, errors.New("audio can only be downmixed to mono")
+	}
+	to := RawPCM(rate, channels)
+	var order binary.ByteOrder = binary.LittleEndian
+	if from.BigEndian {
+		order = binary.BigEndian
+	}
+	c := &converter{
+		r:     bufio.NewReader(r),
+		order: order,
+		in:    from.Channels,
+		out:   channels,
+		step:  float64(from.SampleRate) / float64(rate),
+	}
+	if c.step > 1 {
+		c.taps = lowPass(0.45/c.step, int(math.Ceil(8*c.step)))
+	}
+	return to, c, nil
+}
+
+// lowPass returns the 2*half+1 taps of a Blackman-windowed sinc low-pass filter with the given cutoff, in
+// cycles per sample, normalized to unity gain at DC
+func lowPass(cutoff float64, half int) []float64 {
+	taps := make([]float64, 2*half+1)
+	sum := 0.0
+	for i := range taps {
+		n := float64(i - half)
+		v := 2 * cutoff
+		if n != 0 {
+			v = math.Sin(2*math.Pi*cutoff*n) / (math.Pi * n)
+		}
+		x := float64(i) / float64(2*half)
+		v *= 0.42 - 0.5*math.Cos(2*math.Pi*x) + 0.08*math.Cos(4*math.Pi*x)
+		taps[i] = v
+		sum += v
+	}
+	for i := range taps {
+		taps[i] /= sum
+	}
+	return taps
+}
+
+// converter implements the conversion of Convert()
+type converter struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	// in and out are the number of input and output channels
+	in, out int
+	// step is the distance between output frames, in input frames
+	step float64
+	// taps is the low-pass filter applied to the input frames when downsampling, centred on the filtered
+	// frame; nil when upsampling
+	taps []float64
+
+	// frames are the input frames (downmixed to out channels) from index base on
+	frames [][]int16
+	base   int
+	// pos is the position of the next output frame, in input frames
+	pos float64
+	eof bool
+	// pending output bytes
+	pending []byte
+}
+
+func (c *converter) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		if !c.next() {
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// next produces the next output frame into pending, returning false at the end of the audio
+func (c *converter) next() bool {
+	i := int(c.pos)
+	// make frames i and i+1 available, along with the frames around them used by the filter
+	half := len(c.taps) / 2
+	for !c.eof && c.base+len(c.frames) <= i+1+half {
+		if !c.readFrame() {
+			c.eof = true
+		}
+	}
+	if i-c.base >= len(c.frames) {
+		return false
+	}
+	next := i + 1
+	if next-c.base >= len(c.frames) {
+		next = i
+	}
+	frac := c.pos - float64(i)
+	for ch := 0; ch < c.out; ch++ {
+		v := c.sample(i, ch)*(1-frac) + c.sample(next, ch)*frac
+		c.pending = append(c.pending, 0, 0)
+		binary.LittleEndian.PutUint16(c.pending[len(c.pending)-2:], uint16(clampSample(roundSample(v))))
+	}
+	c.pos += c.step
+	// drop the frames which are no longer needed, keeping those the filter reaches back to
+	if drop := int(c.pos) - half - c.base; drop > 0 {
+		if drop > len(c.frames) {
+			drop = len(c.frames)
+		}
+		c.frames = c.frames[drop:]
+		c.base += drop
+	}
+	return true
+}
+
+// sample returns channel ch of input frame i, low-pass filtered if downsampling. Frames before the start or
+// past the end of the audio repeat the first or last frame.
+func (c *converter) sample(i int, ch int) float64 {
+	if c.taps == nil {
+		return float64(c.frames[i-c.base][ch])
+	}
+	half := len(c.taps) / 2
+	v := 0.0
+	for k, tap := range c.taps {
+		j := i + k - half - c.base
+		if j < 0 {
+			j = 0
+		} else if j >= len(c.frames) {
+			j = len(c.frames) - 1
+		}
+		v += tap * float64(c.frames[j][ch])
+	}
+	return v
+}
+
+// readFrame reads and downmixes an input frame, returning false at the end of the audio
+func (c *converter) readFrame() bool {
+	buf := make([]byte, 2*c.in)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return false
+	}
+	frame := make([]int16, c.out)
+	if c.out == c.in {
+		for ch := range frame {
+			frame[ch] = int16(c.order.Uint16(buf[2*ch:]))
+		}
+	} else {
+		sum := 0
+		for ch := 0; ch < c.in; ch++ {
+			sum += int(int16(c.order.Uint16(buf[2*ch:])))
+		}
+		frame[0] = int16(sum / c.in)
+	}
+	c.frames = append(c.frames, frame)
+	return true
+}
+
+// clampSample converts v to a sample, saturating values out of the 16-bit range (which the filter's
+// ripple can produce near full scale)
+func clampSample(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+func roundSample(v float64) float64 {
+	if v < 0 {
+		return v - 0.5
+	}
+	return v + 0.5
+}
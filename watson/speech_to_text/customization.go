@@ -0,0 +1,334 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Used to specify a custom language model in CreateCustomization()
+type CustomizationMetadata struct {
+	// User-defined name for the custom language model
+	Name string `json:"name"`
+	// The base model to customize (for example, en-US_BroadbandModel)
+	BaseModelName string `json:"base_model_name"`
+	// The dialect of the base model's language, if it differs from the language
+	Dialect string `json:"dialect,omitempty"`
+	// User-defined description of the custom language model
+	Description string `json:"description,omitempty"`
+}
+
+type Customization struct {
+	// Unique identifier for this custom language model
+	CustomizationId string `json:"customization_id"`
+	// Date and time (UTC) the custom model was created
+	Created string `json:"created,omitempty"`
+	// The language of the custom model (for example, en-US)
+	Language string `json:"language,omitempty"`
+	// The dialect of the language of the custom model
+	Dialect string `json:"dialect,omitempty"`
+	// Versions of the base model for which the custom model exists
+	Versions []string `json:"versions,omitempty"`
+	// GUID of the service credentials owning the custom model
+	Owner string `json:"owner,omitempty"`
+	// User-defined name for the custom model
+	Name string `json:"name,omitempty"`
+	// User-defined description of the custom model
+	Description string `json:"description,omitempty"`
+	// The base model of the custom model
+	BaseModelName string `json:"base_model_name,omitempty"`
+	// The state of the custom model = ['pending', 'ready', 'training', 'available', 'upgrading', 'failed']
+	Status string `json:"status,omitempty"`
+	// Percentage of the training or upgrade which has completed
+	Progress int `json:"progress"`
+	// Warnings encountered during training or upgrade
+	Warnings string `json:"warnings,omitempty"`
+}
+
+type CustomizationList struct {
+	Customizations []Customization `json:"customizations"`
+}
+
+type Corpus struct {
+	// The name of the corpus
+	Name string `json:"name"`
+	// Number of words read from the corpus
+	TotalWords int `json:"total_words"`
+	// Number of words from the corpus which are not in the service's base vocabulary
+	OutOfVocabularyWords int `json:"out_of_vocabulary_words"`
+	// The state of the corpus = ['analyzed', 'being_processed', 'undetermined']
+	Status string `json:"status"`
+	// Error message if the corpus could not be analyzed
+	Error string `json:"error,omitempty"`
+}
+
+type CorpusList struct {
+	Corpora []Corpus `json:"corpora"`
+}
+
+type Word struct {
+	// The custom word; ignored by UpdateWord(), which takes the word as argument
+	Word string `json:"word,omitempty"`
+	// Pronunciations of the word, spelled as it sounds (for example, "I. triple E." for "IEEE")
+	SoundsLike []string `json:"sounds_like,omitempty"`
+	// Spelling of the word in transcripts, if it differs from the word itself
+	DisplayAs string `json:"display_as,omitempty"`
+	// Number of times the word was found in corpora; only returned by the service
+	Count int `json:"count,omitempty"`
+	// The corpora the word was read from, or 'user' if it was added directly; only returned by the service
+	Source []string `json:"source,omitempty"`
+	// Problems with the definition of the word; only returned by the service
+	Error []map[string]string `json:"error,omitempty"`
+}
+
+type WordList struct {
+	Words []Word `json:"words"`
+}
+
+// Calls 'POST /v1/customizations' to create a custom language model, returning its customization ID
+func (c Client) CreateCustomization(metadata CustomizationMetadata) (string, error) {
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	body, err = c.watsonClient.MakeRequest("POST", c.version+"/customizations", bytes.NewReader(body), headers)
+	if err != nil {
+		return "", err
+	}
+	var customization Customization
+	err = json.Unmarshal(body, &customization)
+	return customization.CustomizationId, err
+}
+
+// Calls 'GET /v1/customizations' to list the custom language models owned by the service credentials,
+// optionally restricted to a language (if not empty)
+func (c Client) ListCustomizations(language string) (CustomizationList, error) {
+	path := c.version + "/customizations"
+	if len(language) > 0 {
+		path += "?language=" + url.QueryEscape(language)
+	}
+	body, err := c.watsonClient.MakeRequest("GET", path, nil, nil)
+	if err != nil {
+		return CustomizationList{}, err
+	}
+	var customizations CustomizationList
+	err = json.Unmarshal(body, &customizations)
+	return customizations, err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}' to get information on a custom language model
+func (c Client) GetCustomization(customization_id string) (Customization, error) {
+	return c.getCustomization(context.Background(), customization_id)
+}
+
+func (c Client) getCustomization(ctx context.Context, customization_id string) (Customization, error) {
+	body, err := c.watsonClient.MakeRequestContext(ctx, "GET", c.version+"/customizations/"+customization_id, nil, nil)
+	if err != nil {
+		return Customization{}, err
+	}
+	var customization Customization
+	err = json.Unmarshal(body, &customization)
+	return customization, err
+}
+
+// Calls 'DELETE /v1/customizations/{customization_id}' to delete a custom language model
+func (c Client) DeleteCustomization(customization_id string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/customizations/"+customization_id, nil, nil)
+	return err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/train' to train a custom language model on its corpora and
+// words. If user_words_only is true, only words added or modified by the user are trained on (word_type_to_add=user).
+// Training is asynchronous; see WaitForTraining().
+func (c Client) TrainCustomization(customization_id string, user_words_only bool) error {
+	path := c.version + "/customizations/" + customization_id + "/train"
+	if user_words_only {
+		path += "?word_type_to_add=user"
+	}
+	_, err := c.watsonClient.MakeRequest("POST", path, nil, nil)
+	return err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/reset' to remove all corpora and words from a custom language model
+func (c Client) ResetCustomization(customization_id string) error {
+	_, err := c.watsonClient.MakeRequest("POST", c.version+"/customizations/"+customization_id+"/reset", nil, nil)
+	return err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/upgrade_model' to upgrade a custom language model to the latest
+// version of its base model. Upgrading is asynchronous; see WaitForTraining().
+func (c Client) UpgradeCustomization(customization_id string) error {
+	_, err := c.watsonClient.MakeRequest("POST", c.version+"/customizations/"+customization_id+"/upgrade_model", nil, nil)
+	return err
+}
+
+// ErrTrainingFailed is returned by WaitForTraining() if training or upgrading a custom model failed
+var ErrTrainingFailed = errors.New("custom model training failed")
+
+// ErrNotTraining is returned by WaitForTraining() if a custom model does not start training
+var ErrNotTraining = errors.New("custom model is not being trained")
+
+// trainingGracePolls is the number of polls for which WaitForTraining() waits for a model to start training
+const trainingGracePolls = 6
+
+// WaitForTraining polls the status of a custom language model every poll interval, until it is no longer being
+// trained or upgraded. It returns the custom model once its status is 'available', ErrTrainingFailed if it is
+// 'failed', or ctx.Err() if ctx is done first. As the service may take a moment to change the status after
+// TrainCustomization(), a model which is still 'ready' (or 'pending') is waited upon as well, for six polls;
+// ErrNotTraining is returned if it has not started training by then. If poll is not positive, the status is
+// polled every 10 seconds.
+func (c Client) WaitForTraining(ctx context.Context, customization_id string, poll time.Duration) (Customization, error) {
+	if poll <= 0 {
+		poll = 10 * time.Second
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	idle := 0
+	for {
+		customization, err := c.getCustomization(ctx, customization_id)
+		if err != nil {
+			return customization, err
+		}
+		switch customization.Status {
+		case "available":
+			return customization, nil
+		case "failed":
+			return customization, ErrTrainingFailed
+		case "ready", "pending":
+			idle++
+			if idle >= trainingGracePolls {
+				return customization, ErrNotTraining
+			}
+		default:
+			idle = 0
+		}
+		select {
+		case <-ctx.Done():
+			return customization, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/corpora' to list the corpora of a custom language model
+func (c Client) ListCorpora(customization_id string) (CorpusList, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id+"/corpora", nil, nil)
+	if err != nil {
+		return CorpusList{}, err
+	}
+	var corpora CorpusList
+	err = json.Unmarshal(body, &corpora)
+	return corpora, err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/corpora/{corpus_name}' to add a plain text corpus to a custom
+// language model. If allow_overwrite is true, an existing corpus with the same name is replaced.
+// The corpus is analyzed asynchronously; its status is available through GetCorpus().
+func (c Client) AddCorpus(customization_id string, corpus_name string, corpus io.Reader, allow_overwrite bool) error {
+	path := c.version + "/customizations/" + customization_id + "/corpora/" + url.PathEscape(corpus_name)
+	if allow_overwrite {
+		path += "?allow_overwrite=true"
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "text/plain")
+	_, err := c.watsonClient.MakeRequest("POST", path, corpus, headers)
+	return err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/corpora/{corpus_name}' to get information on a corpus
+func (c Client) GetCorpus(customization_id string, corpus_name string) (Corpus, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id+"/corpora/"+url.PathEscape(corpus_name), nil, nil)
+	if err != nil {
+		return Corpus{}, err
+	}
+	var corpus Corpus
+	err = json.Unmarshal(body, &corpus)
+	return corpus, err
+}
+
+// Calls 'DELETE /v1/customizations/{customization_id}/corpora/{corpus_name}' to delete a corpus
+func (c Client) DeleteCorpus(customization_id string, corpus_name string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/customizations/"+customization_id+"/corpora/"+url.PathEscape(corpus_name), nil, nil)
+	return err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/words' to list the words of a custom language model.
+// word_type is one of 'all' (the default, if empty), 'user' or 'corpora'.
+func (c Client) ListWords(customization_id string, word_type string) (WordList, error) {
+	path := c.version + "/customizations/" + customization_id + "/words"
+	if len(word_type) > 0 {
+		path += "?word_type=" + url.QueryEscape(word_type)
+	}
+	body, err := c.watsonClient.MakeRequest("GET", path, nil, nil)
+	if err != nil {
+		return WordList{}, err
+	}
+	var words WordList
+	err = json.Unmarshal(body, &words)
+	return words, err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/words' to add words to a custom language model, or to
+// modify existing ones
+func (c Client) AddWords(customization_id string, words []Word) error {
+	body, err := json.Marshal(WordList{Words: words})
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	_, err = c.watsonClient.MakeRequest("POST", c.version+"/customizations/"+customization_id+"/words", bytes.NewReader(body), headers)
+	return err
+}
+
+// Calls 'PUT /v1/customizations/{customization_id}/words/{word_name}' to add or modify a single word, with
+// the given pronunciations and display spelling (either may be empty)
+func (c Client) UpdateWord(customization_id string, word_name string, sounds_like []string, display_as string) error {
+	body, err := json.Marshal(Word{SoundsLike: sounds_like, DisplayAs: display_as})
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	_, err = c.watsonClient.MakeRequest("PUT", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word_name), bytes.NewReader(body), headers)
+	return err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/words/{word_name}' to get information on a word
+func (c Client) GetWord(customization_id string, word_name string) (Word, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word_name), nil, nil)
+	if err != nil {
+		return Word{}, err
+	}
+	var word Word
+	err = json.Unmarshal(body, &word)
+	return word, err
+}
+
+// Calls 'DELETE /v1/customizations/{customization_id}/words/{word_name}' to delete a word
+func (c Client) DeleteWord(customization_id string, word_name string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word_name), nil, nil)
+	return err
+}
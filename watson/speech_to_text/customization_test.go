@@ -0,0 +1,106 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWaitForTraining(t *testing.T) {
+	polls := 0
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/customizations/cust-1" {
+			t.Errorf("unexpected request %s\n", r.URL)
+		}
+		polls++
+		status := "training"
+		if polls == 3 {
+			status = "available"
+		}
+		w.Write([]byte(`{"customization_id": "cust-1", "status": "` + status + `", "progress": 100}`))
+	})
+	defer closeServer()
+
+	customization, err := c.WaitForTraining(context.Background(), "cust-1", time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitForTraining() failed %#v\n", err)
+		return
+	}
+	if customization.Status != "available" || polls != 3 {
+		t.Errorf("WaitForTraining() returned %#v after %d polls\n", customization, polls)
+	}
+
+	// a zero poll interval falls back to the default rather than panicking
+	polls = 2
+	if _, err := c.WaitForTraining(context.Background(), "cust-1", 0); err != nil {
+		t.Errorf("WaitForTraining() with zero poll interval failed %#v\n", err)
+	}
+}
+
+func TestWaitForTrainingIdle(t *testing.T) {
+	polls := 0
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Write([]byte(`{"customization_id": "cust-1", "status": "ready"}`))
+	})
+	defer closeServer()
+
+	// a model which never starts training is not waited upon forever
+	if _, err := c.WaitForTraining(context.Background(), "cust-1", time.Millisecond); err != ErrNotTraining || polls != trainingGracePolls {
+		t.Errorf("WaitForTraining() of an idle model returned %#v after %d polls\n", err, polls)
+	}
+
+	// a hung poll is abandoned when ctx is done
+	hung := make(chan struct{})
+	c, closeHung := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	})
+	defer closeHung()
+	defer close(hung)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForTraining(ctx, "cust-1", time.Millisecond); err == nil {
+		t.Errorf("WaitForTraining() of a hung service succeeded\n")
+	}
+}
+
+func TestAddWords(t *testing.T) {
+	var got WordList
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/customizations/cust-1/words" {
+			t.Errorf("unexpected request %s %s\n", r.Method, r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+	})
+	defer closeServer()
+
+	words := []Word{{Word: "IEEE", SoundsLike: []string{"I. triple E."}, DisplayAs: "IEEE"}}
+	err := c.AddWords("cust-1", words)
+	if err != nil {
+		t.Errorf("AddWords() failed %#v\n", err)
+		return
+	}
+	if !reflect.DeepEqual(got.Words, words) {
+		t.Errorf("AddWords() sent %#v\n", got)
+	}
+}
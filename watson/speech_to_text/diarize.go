@@ -0,0 +1,128 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnknownSpeaker identifies words for which no speaker label has been received (yet)
+const UnknownSpeaker = -1
+
+// SpeakerUtterance is a run of consecutive words spoken by a single speaker
+type SpeakerUtterance struct {
+	// Speaker identifier, or UnknownSpeaker
+	Speaker int `json:"speaker"`
+	// Start of the utterance, in seconds
+	Start float64 `json:"start"`
+	// End of the utterance, in seconds
+	End        float64      `json:"end"`
+	Transcript string       `json:"transcript"`
+	Words      []WordTiming `json:"words"`
+}
+
+// Diarizer merges the speaker labels of recognition events with the word timestamps of their final results,
+// to tell who said what. Events are added as they are received from a streaming session, and Utterances()
+// reflects the labels and results received so far; use Diarize() for the event returned by Recognize().
+// Recognition must be requested with the SpeakerLabels option.
+type Diarizer struct {
+	transcript *Transcript
+	// labels indexed by their start time, as the service revises labels by sending new ones for the same word
+	labels map[float64]SpeakerLabel
+	final  bool
+}
+
+// NewDiarizer creates an empty diarizer
+func NewDiarizer() *Diarizer {
+	return &Diarizer{transcript: NewTranscript(TranscriptOptions{}), labels: make(map[float64]SpeakerLabel)}
+}
+
+// Diarize returns the speaker utterances of a complete recognition, such as returned by Recognize()
+func Diarize(event Event) ([]SpeakerUtterance, error) {
+	d := NewDiarizer()
+	d.Add(event)
+	return d.Utterances()
+}
+
+// Add adds the final results and speaker labels of event
+func (d *Diarizer) Add(event Event) {
+	d.transcript.Add(event)
+	for _, label := range event.SpeakerLabels {
+		d.labels[label.From] = label
+		if label.Final {
+			d.final = true
+		}
+	}
+}
+
+// Final tells whether the service has sent its final revision of the speaker labels
+func (d *Diarizer) Final() bool {
+	return d.final
+}
+
+// Utterances returns the speaker utterances of the final results added so far. Utterances do not span
+// results; within a result, a new utterance starts whenever the speaker changes.
+func (d *Diarizer) Utterances() ([]SpeakerUtterance, error) {
+	labels := make([]SpeakerLabel, 0, len(d.labels))
+	for _, label := range d.labels {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].From < labels[j].From })
+
+	var utterances []SpeakerUtterance
+	for _, result := range d.transcript.results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		timings, err := result.Alternatives[0].WordTimings()
+		if err != nil {
+			return nil, err
+		}
+		var u *SpeakerUtterance
+		for _, w := range timings {
+			if w.Word == hesitation {
+				continue
+			}
+			speaker := speakerAt(labels, w)
+			if u == nil || u.Speaker != speaker {
+				utterances = append(utterances, SpeakerUtterance{Speaker: speaker, Start: w.Start})
+				u = &utterances[len(utterances)-1]
+			}
+			u.End = w.End
+			u.Words = append(u.Words, w)
+		}
+		u = nil
+	}
+	for i := range utterances {
+		words := make([]string, len(utterances[i].Words))
+		for j, w := range utterances[i].Words {
+			words[j] = w.Word
+		}
+		utterances[i].Transcript = strings.Join(words, " ")
+	}
+	return utterances, nil
+}
+
+// speakerAt returns the speaker of the label covering the middle of word w, given labels sorted by start time
+func speakerAt(labels []SpeakerLabel, w WordTiming) int {
+	mid := (w.Start + w.End) / 2
+	i := sort.Search(len(labels), func(i int) bool { return labels[i].From > mid })
+	if i > 0 && labels[i-1].To >= mid {
+		return labels[i-1].Speaker
+	}
+	return UnknownSpeaker
+}
@@ -0,0 +1,72 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiarizerStreaming(t *testing.T) {
+	var events []Event
+	err := json.Unmarshal([]byte(`[
+{"results": [{"final": true, "alternatives": [{"transcript": "hello how are you ",
+  "timestamps": [["hello", 0.1, 0.5], ["how", 0.8, 1.0], ["are", 1.0, 1.1], ["you", 1.1, 1.3]]}]}], "result_index": 0},
+{"speaker_labels": [{"from": 0.1, "to": 0.5, "speaker": 0, "confidence": 0.6, "final": false},
+  {"from": 0.8, "to": 1.0, "speaker": 0, "confidence": 0.5, "final": false}]},
+{"speaker_labels": [{"from": 0.8, "to": 1.0, "speaker": 1, "confidence": 0.7, "final": false},
+  {"from": 1.0, "to": 1.1, "speaker": 1, "confidence": 0.7, "final": false},
+  {"from": 1.1, "to": 1.3, "speaker": 1, "confidence": 0.7, "final": true}]}
+]`), &events)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed %#v\n", err)
+	}
+
+	d := NewDiarizer()
+	d.Add(events[0])
+	utterances, _ := d.Utterances()
+	if len(utterances) != 1 || utterances[0].Speaker != UnknownSpeaker {
+		t.Errorf("Utterances() without labels returned %#v\n", utterances)
+	}
+
+	d.Add(events[1])
+	utterances, _ = d.Utterances()
+	if len(utterances) != 2 || utterances[0].Transcript != "hello how" || utterances[1].Speaker != UnknownSpeaker {
+		t.Errorf("Utterances() with partial labels returned %#v\n", utterances)
+	}
+
+	d.Add(events[2])
+	utterances, err = d.Utterances()
+	if err != nil {
+		t.Errorf("Utterances() failed %#v\n", err)
+		return
+	}
+	want := []struct {
+		speaker    int
+		start, end float64
+		transcript string
+	}{{0, 0.1, 0.5, "hello"}, {1, 0.8, 1.3, "how are you"}}
+	if len(utterances) != len(want) || !d.Final() {
+		t.Errorf("Utterances() returned %#v\n", utterances)
+		return
+	}
+	for i, w := range want {
+		u := utterances[i]
+		if u.Speaker != w.speaker || u.Start != w.start || u.End != w.end || u.Transcript != w.transcript {
+			t.Errorf("utterance %d is %#v, wanted %#v\n", i, u, w)
+		}
+	}
+}
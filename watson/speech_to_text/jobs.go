@@ -0,0 +1,255 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type CallbackRegistration struct {
+	// Current status of the callback URL: 'created' if newly registered, 'already created' otherwise.
+	Status string `json:"status"`
+	// The callback URL that is successfully registered.
+	URL string `json:"url"`
+}
+
+// Calls 'POST /v1/register_callback' to register a callback URL for use with asynchronous recognition jobs.
+// The service verifies the URL by sending it a GET request with a challenge string (see CallbackHandler).
+// If user_secret is not empty, the service signs its callback requests with it.
+func (c Client) RegisterCallback(callback_url string, user_secret string) (CallbackRegistration, error) {
+	q := url.Values{}
+	q.Set("callback_url", callback_url)
+	if len(user_secret) > 0 {
+		q.Set("user_secret", user_secret)
+	}
+	body, err := c.watsonClient.MakeRequest("POST", c.version+"/register_callback?"+q.Encode(), nil, nil)
+	if err != nil {
+		return CallbackRegistration{}, err
+	}
+	var registration CallbackRegistration
+	err = json.Unmarshal(body, &registration)
+	return registration, err
+}
+
+// Calls 'POST /v1/unregister_callback' to unregister a callback URL
+func (c Client) UnregisterCallback(callback_url string) error {
+	q := url.Values{}
+	q.Set("callback_url", callback_url)
+	_, err := c.watsonClient.MakeRequest("POST", c.version+"/unregister_callback?"+q.Encode(), nil, nil)
+	return err
+}
+
+// JobOptions contains the parameters of an asynchronous recognition job
+type JobOptions struct {
+	RecognizeOptions
+	// Registered callback URL to notify of job events; if empty, the job status must be polled with GetJob.
+	CallbackURL string
+	// Events to notify the callback of, e.g. "recognitions.started", "recognitions.completed",
+	// "recognitions.completed_with_results" or "recognitions.failed"; the service default if empty.
+	Events []string
+	// Token included in callback notifications, to identify the job.
+	UserToken string
+	// Minutes for which the results remain available; the service default if zero.
+	ResultsTTL int
+}
+
+type Job struct {
+	// The ID of the job.
+	Id string `json:"id"`
+	// Current status of the job = ['waiting', 'processing', 'completed', 'failed'].
+	Status string `json:"status"`
+	// Date and time in Coordinated Universal Time (UTC) at which the job was created.
+	Created string `json:"created,omitempty"`
+	// Date and time in Coordinated Universal Time (UTC) at which the job was last updated by the service.
+	Updated string `json:"updated,omitempty"`
+	// URI for information about the job.
+	URL string `json:"url,omitempty"`
+	// The user token associated with the job, if it was created with a callback URL.
+	UserToken string `json:"user_token,omitempty"`
+	// Results of the recognition, once the job has completed.
+	Results []Event `json:"results,omitempty"`
+	// Warnings about invalid query parameters included with the request.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type JobList struct {
+	Recognitions []Job `json:"recognitions"`
+}
+
+// Calls 'POST /v1/recognitions' to create an asynchronous recognition job for audio of type content_type.
+// As with Recognize, audio of unknown length is streamed to the service with chunked transfer encoding.
+func (c Client) CreateJob(ctx context.Context, audio io.Reader, content_type string, opts JobOptions) (Job, error) {
+	q := opts.RecognizeOptions.query()
+	if len(opts.CallbackURL) > 0 {
+		q.Set("callback_url", opts.CallbackURL)
+	}
+	if len(opts.Events) > 0 {
+		q.Set("events", strings.Join(opts.Events, ","))
+	}
+	if len(opts.UserToken) > 0 {
+		q.Set("user_token", opts.UserToken)
+	}
+	if opts.ResultsTTL > 0 {
+		q.Set("results_ttl", strconv.Itoa(opts.ResultsTTL))
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", content_type)
+	headers.Set("Accept", "application/json")
+	body, err := c.watsonClient.MakeRequestContext(ctx, "POST", c.version+"/recognitions?"+q.Encode(), audio, headers)
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	err = json.Unmarshal(body, &job)
+	return job, err
+}
+
+// Calls 'GET /v1/recognitions' to list the status of the most recent asynchronous jobs
+func (c Client) ListJobs() (JobList, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/recognitions", nil, nil)
+	if err != nil {
+		return JobList{}, err
+	}
+	var jobs JobList
+	err = json.Unmarshal(body, &jobs)
+	return jobs, err
+}
+
+// Calls 'GET /v1/recognitions/{id}' to check the status of a job, and retrieve its results once completed
+func (c Client) GetJob(id string) (Job, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/recognitions/"+id, nil, nil)
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	err = json.Unmarshal(body, &job)
+	return job, err
+}
+
+// Calls 'DELETE /v1/recognitions/{id}' to delete a job and its results
+func (c Client) DeleteJob(id string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/recognitions/"+id, nil, nil)
+	return err
+}
+
+// JobNotification is sent by the service to a registered callback URL
+type JobNotification struct {
+	// The ID of the job.
+	Id string `json:"id"`
+	// The event being notified, e.g. "recognitions.completed_with_results".
+	Event string `json:"event"`
+	// The user token given when creating the job.
+	UserToken string `json:"user_token,omitempty"`
+	// Date and time in Coordinated Universal Time (UTC) of the notification.
+	Created string `json:"created,omitempty"`
+	// Results of the recognition, for the "recognitions.completed_with_results" event.
+	Results []Event `json:"results,omitempty"`
+}
+
+// CallbackHandler is an http.Handler implementing a callback URL for asynchronous recognition jobs. It answers
+// the verification requests of the service, and delivers the notifications it receives on a channel. If
+// created with a secret, requests whose X-Callback-Signature does not match are rejected.
+type CallbackHandler struct {
+	secret        string
+	notifications chan JobNotification
+	maxBody       int64
+}
+
+// maxCallbackBody bounds the notifications accepted by a CallbackHandler; those with results carry the
+// whole transcript of the job, which is large for long audio but not unbounded
+const maxCallbackBody = 16 << 20
+
+// NewCallbackHandler creates a callback handler for the user_secret given to RegisterCallback (which may be
+// empty). Notifications are delivered on the returned channel, which buffers up to buffer of them; when
+// the channel is full, the service is asked to retry later.
+func NewCallbackHandler(user_secret string, buffer int) (*CallbackHandler, <-chan JobNotification) {
+	h := &CallbackHandler{secret: user_secret, notifications: make(chan JobNotification, buffer), maxBody: maxCallbackBody}
+	return h, h.notifications
+}
+
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		// the service verifies callback URLs by having them echo a challenge string
+		challenge := r.URL.Query().Get("challenge_string")
+		if !h.verify(r, []byte(challenge)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, challenge)
+	case "POST":
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !h.verify(r, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var n JobNotification
+		err = json.Unmarshal(body, &n)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		select {
+		case h.notifications <- n:
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "notification queue full", http.StatusServiceUnavailable)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify checks the X-Callback-Signature header of r, the base64 encoded HMAC-SHA1 of payload keyed by the
+// user secret
+func (h *CallbackHandler) verify(r *http.Request, payload []byte) bool {
+	if len(h.secret) == 0 {
+		return true
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Callback-Signature"))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, CallbackSignature(h.secret, payload))
+}
+
+// CallbackSignature returns the signature the service computes for payload, given the user secret
+func CallbackSignature(user_secret string, payload []byte) []byte {
+	mac := hmac.New(sha1.New, []byte(user_secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,119 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateJob(t *testing.T) {
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method != "POST" || r.URL.Path != "/v1/recognitions" || q.Get("callback_url") != "https://example.com/cb" ||
+			q.Get("events") != "recognitions.completed_with_results,recognitions.failed" || q.Get("user_token") != "job-1" {
+			t.Errorf("unexpected request %s %s\n", r.Method, r.URL)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "4bd734c0", "status": "waiting", "created": "2016-08-17T19:15:17.926Z"}`))
+	})
+	defer closeServer()
+
+	job, err := c.CreateJob(context.Background(), strings.NewReader("fLaC"), "audio/flac", JobOptions{
+		CallbackURL: "https://example.com/cb",
+		Events:      []string{"recognitions.completed_with_results", "recognitions.failed"},
+		UserToken:   "job-1",
+	})
+	if err != nil {
+		t.Errorf("CreateJob() failed %#v\n", err)
+		return
+	}
+	if job.Id != "4bd734c0" || job.Status != "waiting" {
+		t.Errorf("CreateJob() returned %#v\n", job)
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	h, notifications := NewCallbackHandler("s3cret", 1)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	sign := func(req *http.Request, payload []byte) {
+		req.Header.Set("X-Callback-Signature", base64.StdEncoding.EncodeToString(CallbackSignature("s3cret", payload)))
+	}
+
+	// verification of the callback URL
+	req, _ := http.NewRequest("GET", ts.URL+"?challenge_string=abc123", nil)
+	sign(req, []byte("abc123"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed %#v\n", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "abc123" {
+		t.Errorf("challenge answered with %d %q\n", resp.StatusCode, body)
+	}
+
+	// notification with an invalid signature
+	payload := []byte(`{"id": "4bd734c0", "event": "recognitions.completed_with_results", "user_token": "job-1",
+		"results": [{"results": [{"final": true, "alternatives": [{"transcript": "hello "}]}], "result_index": 0}]}`)
+	req, _ = http.NewRequest("POST", ts.URL, bytes.NewReader(payload))
+	sign(req, []byte("something else"))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed %#v\n", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("forged notification answered with %d\n", resp.StatusCode)
+	}
+
+	// valid notification
+	req, _ = http.NewRequest("POST", ts.URL, bytes.NewReader(payload))
+	sign(req, payload)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed %#v\n", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("notification answered with %d\n", resp.StatusCode)
+	}
+	n := <-notifications
+	if n.Id != "4bd734c0" || n.UserToken != "job-1" || len(n.Results) != 1 || n.Results[0].Results[0].Alternatives[0].Transcript != "hello " {
+		t.Errorf("delivered notification %#v\n", n)
+	}
+
+	// notification larger than the handler accepts
+	h.maxBody = int64(len(payload)) - 1
+	req, _ = http.NewRequest("POST", ts.URL, bytes.NewReader(payload))
+	sign(req, payload)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed %#v\n", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized notification answered with %d\n", resp.StatusCode)
+	}
+}
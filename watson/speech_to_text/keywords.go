@@ -0,0 +1,139 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeywordHit is a match of a keyword in the audio
+type KeywordHit struct {
+	// The keyword, as given in RecognizeOptions.Keywords
+	Keyword string `json:"keyword"`
+	// The keyword normalized to the spoken phrase that matched
+	NormalizedText string `json:"normalized_text"`
+	// Start and end of the match, in seconds
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"confidence"`
+	// Index of the result the match was found in
+	ResultIndex int `json:"result_index"`
+}
+
+// KeywordSpotter accumulates the keyword matches and word alternatives of the final results of a stream,
+// and calls back the functions registered with On() as soon as keywords are spotted. Events are added as
+// they are received; a KeywordSpotter is safe for use by multiple goroutines.
+type KeywordSpotter struct {
+	mu           sync.Mutex
+	hits         []KeywordHit
+	alternatives []WordAlternativeResults
+	// seen identifies the matches and word alternatives added already, as results may be delivered more than once
+	seen      map[KeywordHit]bool
+	seenWords map[[2]float64]bool
+	callbacks map[string][]func(KeywordHit)
+}
+
+// NewKeywordSpotter creates an empty keyword spotter
+func NewKeywordSpotter() *KeywordSpotter {
+	return &KeywordSpotter{
+		seen:      make(map[KeywordHit]bool),
+		seenWords: make(map[[2]float64]bool),
+		callbacks: make(map[string][]func(KeywordHit)),
+	}
+}
+
+// On registers fn to be called for every match of keyword, or of any keyword if keyword is empty. Callbacks are
+// called from Add(), in the order of the matches, and must not call methods of the spotter.
+func (k *KeywordSpotter) On(keyword string, fn func(KeywordHit)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.callbacks[keyword] = append(k.callbacks[keyword], fn)
+}
+
+// Add adds the keyword matches and word alternatives of the final results of event
+func (k *KeywordSpotter) Add(event Event) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for i, result := range event.Results {
+		if !result.Final {
+			continue
+		}
+		var hits []KeywordHit
+		for keyword, matches := range result.KeywordsResult {
+			for _, m := range matches {
+				hit := KeywordHit{
+					Keyword:        keyword,
+					NormalizedText: m.NormalizedText,
+					Start:          m.StartTime,
+					End:            m.EndTime,
+					Confidence:     m.Confidence,
+					ResultIndex:    event.ResultIndex + i,
+				}
+				if !k.seen[hit] {
+					k.seen[hit] = true
+					hits = append(hits, hit)
+				}
+			}
+		}
+		sort.Slice(hits, func(i, j int) bool { return hits[i].Start < hits[j].Start })
+		for _, hit := range hits {
+			k.hits = append(k.hits, hit)
+			for _, fn := range k.callbacks[hit.Keyword] {
+				fn(hit)
+			}
+			for _, fn := range k.callbacks[""] {
+				fn(hit)
+			}
+		}
+		for _, alt := range result.WordAlternatives {
+			key := [2]float64{alt.StartTime, alt.EndTime}
+			if !k.seenWords[key] {
+				k.seenWords[key] = true
+				k.alternatives = append(k.alternatives, alt)
+			}
+		}
+	}
+}
+
+// Hits returns the keyword matches spotted so far, ordered by time
+func (k *KeywordSpotter) Hits() []KeywordHit {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	hits := append([]KeywordHit(nil), k.hits...)
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Start < hits[j].Start })
+	return hits
+}
+
+// Counts returns the number of matches of each keyword spotted so far
+func (k *KeywordSpotter) Counts() map[string]int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	counts := make(map[string]int)
+	for _, hit := range k.hits {
+		counts[hit.Keyword]++
+	}
+	return counts
+}
+
+// WordAlternatives returns the word alternatives of the final results added so far, ordered by time
+func (k *KeywordSpotter) WordAlternatives() []WordAlternativeResults {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	alternatives := append([]WordAlternativeResults(nil), k.alternatives...)
+	sort.SliceStable(alternatives, func(i, j int) bool { return alternatives[i].StartTime < alternatives[j].StartTime })
+	return alternatives
+}
@@ -0,0 +1,72 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKeywordSpotter(t *testing.T) {
+	var events []Event
+	err := json.Unmarshal([]byte(`[
+{"results": [{"final": true, "alternatives": [{"transcript": "my account number is locked "}],
+  "keywords_result": {"account": [{"normalized_text": "account", "start_time": 0.6, "end_time": 1.05, "confidence": 0.94}],
+    "locked": [{"normalized_text": "locked", "start_time": 1.9, "end_time": 2.3, "confidence": 0.81}]},
+  "word_alternatives": [{"start_time": 1.9, "end_time": 2.3, "alternatives": [{"word": "locked", "confidence": 0.81}, {"word": "blocked", "confidence": 0.12}]}]}],
+ "result_index": 0},
+{"results": [{"final": true, "alternatives": [{"transcript": "please unlock my account "}],
+  "keywords_result": {"account": [{"normalized_text": "account", "start_time": 4.1, "end_time": 4.6, "confidence": 0.9}]}}],
+ "result_index": 1}
+]`), &events)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed %#v\n", err)
+	}
+
+	k := NewKeywordSpotter()
+	var spotted, all []float64
+	k.On("account", func(hit KeywordHit) { spotted = append(spotted, hit.Start) })
+	k.On("", func(hit KeywordHit) { all = append(all, hit.Start) })
+	for _, e := range events {
+		k.Add(e)
+	}
+	// results delivered again are not counted twice
+	k.Add(events[1])
+
+	if len(spotted) != 2 || spotted[0] != 0.6 || spotted[1] != 4.1 || len(all) != 3 {
+		t.Errorf("callbacks called for %v and %v\n", spotted, all)
+	}
+	if counts := k.Counts(); counts["account"] != 2 || counts["locked"] != 1 {
+		t.Errorf("Counts() returned %#v\n", counts)
+	}
+	if hits := k.Hits(); len(hits) != 3 || hits[1].Keyword != "locked" || hits[2].ResultIndex != 1 {
+		t.Errorf("Hits() returned %#v\n", hits)
+	}
+	if alternatives := k.WordAlternatives(); len(alternatives) != 1 || alternatives[0].Alternatives[1].Word != "blocked" {
+		t.Errorf("WordAlternatives() returned %#v\n", alternatives)
+	}
+}
+
+func TestKeywordOptions(t *testing.T) {
+	opts := RecognizeOptions{Keywords: []string{"account", "locked"}, KeywordsThreshold: 0.5, WordAlternativesThreshold: 0.1}
+	q := opts.query()
+	if q.Get("keywords") != "account,locked" || q.Get("keywords_threshold") != "0.5" || q.Get("word_alternatives_threshold") != "0.1" {
+		t.Errorf("query() returned %v\n", q)
+	}
+	if keywords, ok := opts.startOptions()["keywords"].([]string); !ok || len(keywords) != 2 {
+		t.Errorf("startOptions() returned %#v\n", opts.startOptions())
+	}
+}
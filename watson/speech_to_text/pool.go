@@ -0,0 +1,300 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// PoolConfig configures a Pool
+type PoolConfig struct {
+	// Maximum number of concurrent streams of each client (that is, of each service credential); unlimited if zero.
+	MaxStreams int
+	// Models maps languages (for example, "en-US") to the model used to recognize them. Languages which are
+	// not mapped use the model of the language with the highest sampling rate, from ListModels().
+	Models map[string]string
+}
+
+// StreamStats describes a stream opened through a Pool
+type StreamStats struct {
+	Id       int
+	Language string
+	Model    string
+	// Index of the client of the stream, in the clients of the pool
+	Client  int
+	Started time.Time
+	State   RecognizeState
+	// Bytes of audio written to the stream
+	BytesWritten int64
+	// Events received from the service, and final results among them
+	Events       int
+	FinalResults int
+	// Interim results dropped by the OverflowDropInterim policy
+	Dropped int
+	// Time the last event was received
+	LastEvent time.Time
+}
+
+// Pool opens recognition streams for many concurrent audio channels, spreading them across clients using
+// different service credentials, within a limit of concurrent streams per client. Streams are routed to
+// a model according to the language of their audio. A Pool is safe for use by multiple goroutines.
+type Pool struct {
+	clients    []Client
+	maxStreams int
+
+	// now returns the current time; it is replaced in tests
+	now func() time.Time
+
+	mu     sync.Mutex
+	models map[string]string
+	// listed is set once the models of the service have been listed
+	listed bool
+	// listing is closed when the models being listed by another goroutine have been merged
+	listing chan struct{}
+	// listErr is the error of the last failure to list the models, which is returned until listRetry
+	listErr     error
+	listRetry   time.Time
+	listBackoff time.Duration
+	// active counts the streams of each client
+	active []int
+	// released is closed, and replaced, whenever a stream ends
+	released chan struct{}
+	streams  map[int]*PooledStream
+	nextId   int
+}
+
+// PooledStream is a recognition session opened through a Pool. Its slot in the pool is released once the
+// session has ended.
+type PooledStream struct {
+	*RecognizeSession
+	pool *Pool
+
+	// mu guards stats
+	mu    sync.Mutex
+	stats StreamStats
+}
+
+// NewPool creates a pool of streams using clients
+func NewPool(clients []Client, cfg PoolConfig) *Pool {
+	models := make(map[string]string)
+	for language, model := range cfg.Models {
+		models[language] = model
+	}
+	return &Pool{
+		clients:    clients,
+		maxStreams: cfg.MaxStreams,
+		now:        time.Now,
+		models:     models,
+		active:     make([]int, len(clients)),
+		released:   make(chan struct{}),
+		streams:    make(map[int]*PooledStream),
+	}
+}
+
+// Open opens a stream for audio in language, of type content_type. The model is opts.Model if set, and
+// otherwise chosen by language. If all clients have MaxStreams streams, Open waits until a stream ends,
+// or until ctx is done.
+func (p *Pool) Open(ctx context.Context, language string, content_type string, opts RecognizeOptions) (*PooledStream, error) {
+	if len(opts.Model) == 0 {
+		model, err := p.route(ctx, language)
+		if err != nil {
+			return nil, err
+		}
+		opts.Model = model
+	}
+	client, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PooledStream{pool: p}
+	p.mu.Lock()
+	p.nextId++
+	s.stats = StreamStats{Id: p.nextId, Language: language, Model: opts.Model, Client: client, Started: time.Now()}
+	p.mu.Unlock()
+
+	cfg := streamConfig{bufferSize: opts.BufferSize, overflow: opts.Overflow, keepalive: opts.Keepalive, observe: s.observe}
+	if cfg.bufferSize <= 0 {
+		cfg.bufferSize = 100
+	}
+	s.RecognizeSession, err = p.clients[client].dialSession(opts.Model, content_type, opts.startOptions(), cfg)
+	if err != nil {
+		p.release(client, 0)
+		return nil, err
+	}
+	id := s.stats.Id
+	p.mu.Lock()
+	p.streams[id] = s
+	p.mu.Unlock()
+	go func() {
+		<-s.Done()
+		p.release(client, id)
+	}()
+	return s, nil
+}
+
+// route returns the model for language. The models of the service are listed once, outside of p.mu; after a
+// failure, listing is not retried for a while, doubling from a second up to a minute.
+func (p *Pool) route(ctx context.Context, language string) (string, error) {
+	for {
+		p.mu.Lock()
+		if model, ok := p.models[language]; ok {
+			p.mu.Unlock()
+			return model, nil
+		}
+		if p.listed || len(p.clients) == 0 {
+			p.mu.Unlock()
+			return "", errors.New("no model for language " + language)
+		}
+		if p.listing != nil {
+			listing := p.listing
+			p.mu.Unlock()
+			select {
+			case <-listing:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			continue
+		}
+		if p.listErr != nil && p.now().Before(p.listRetry) {
+			err := p.listErr
+			p.mu.Unlock()
+			return "", err
+		}
+		p.listing = make(chan struct{})
+		p.mu.Unlock()
+
+		models, err := p.clients[0].ListModels()
+		p.mu.Lock()
+		close(p.listing)
+		p.listing = nil
+		if err != nil {
+			p.listBackoff *= 2
+			if p.listBackoff < time.Second {
+				p.listBackoff = time.Second
+			} else if p.listBackoff > time.Minute {
+				p.listBackoff = time.Minute
+			}
+			p.listErr = err
+			p.listRetry = p.now().Add(p.listBackoff)
+			p.mu.Unlock()
+			return "", err
+		}
+		best := make(map[string]Model)
+		for _, m := range models.Models {
+			if m.Rate > best[m.Language].Rate {
+				best[m.Language] = m
+			}
+		}
+		// configured routes take precedence
+		for language, m := range best {
+			if _, ok := p.models[language]; !ok {
+				p.models[language] = m.Name
+			}
+		}
+		p.listed = true
+		p.listErr = nil
+		p.mu.Unlock()
+	}
+}
+
+// acquire reserves a stream of the least busy client, returning its index
+func (p *Pool) acquire(ctx context.Context) (int, error) {
+	if len(p.clients) == 0 {
+		return 0, errors.New("pool has no clients")
+	}
+	for {
+		p.mu.Lock()
+		client := 0
+		for i := range p.active {
+			if p.active[i] < p.active[client] {
+				client = i
+			}
+		}
+		if p.maxStreams <= 0 || p.active[client] < p.maxStreams {
+			p.active[client]++
+			p.mu.Unlock()
+			return client, nil
+		}
+		released := p.released
+		p.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// release frees the stream reserved on client, and forgets the stream with the given id
+func (p *Pool) release(client int, id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[client]--
+	delete(p.streams, id)
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// Stats returns the statistics of the streams which are open
+func (p *Pool) Stats() []StreamStats {
+	p.mu.Lock()
+	streams := make([]*PooledStream, 0, len(p.streams))
+	for _, s := range p.streams {
+		streams = append(streams, s)
+	}
+	p.mu.Unlock()
+	stats := make([]StreamStats, len(streams))
+	for i, s := range streams {
+		stats[i] = s.Stats()
+	}
+	return stats
+}
+
+// Stats returns the statistics of the stream
+func (s *PooledStream) Stats() StreamStats {
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+	stats.State = s.State()
+	stats.Dropped = s.Dropped()
+	return stats
+}
+
+// Write sends audio to the service, counting it in the stream statistics
+func (s *PooledStream) Write(p []byte) (int, error) {
+	n, err := s.RecognizeSession.Write(p)
+	s.mu.Lock()
+	s.stats.BytesWritten += int64(n)
+	s.mu.Unlock()
+	return n, err
+}
+
+// observe counts the events received by the stream
+func (s *PooledStream) observe(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Events++
+	s.stats.LastEvent = time.Now()
+	for _, result := range event.Results {
+		if result.Final {
+			s.stats.FinalResults++
+		}
+	}
+}
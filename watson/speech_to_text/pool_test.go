@@ -0,0 +1,168 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// newFakeServiceClient returns a client for a local fake of the service, answering token requests, listing
+// models and recognizing audio over websockets with fakeRecognizer
+func newFakeServiceClient(t *testing.T, models chan<- string) (Client, func()) {
+	return newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/authorization/"):
+			w.Write([]byte("token"))
+		case r.URL.Path == "/v1/models":
+			w.Write([]byte(`{"models": [{"name": "fr-FR_NarrowbandModel", "language": "fr-FR", "rate": 8000},
+				{"name": "fr-FR_BroadbandModel", "language": "fr-FR", "rate": 16000}]}`))
+		default:
+			if models != nil {
+				models <- r.URL.Query().Get("model")
+			}
+			websocket.Handler(fakeRecognizer).ServeHTTP(w, r)
+		}
+	})
+}
+
+func TestPool(t *testing.T) {
+	models := make(chan string, 3)
+	c, closeService := newFakeServiceClient(t, models)
+	defer closeService()
+	p := NewPool([]Client{c}, PoolConfig{MaxStreams: 1, Models: map[string]string{"en-US": "en-US_NarrowbandModel"}})
+
+	s, err := p.Open(context.Background(), "en-US", "audio/l16;rate=8000", RecognizeOptions{})
+	if err != nil {
+		t.Fatalf("Open() failed %#v\n", err)
+	}
+	if model := <-models; model != "en-US_NarrowbandModel" {
+		t.Errorf("en-US routed to %s\n", model)
+	}
+
+	// the pool is full until the stream ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Open(ctx, "fr-FR", "audio/l16;rate=16000", RecognizeOptions{}); err != context.DeadlineExceeded {
+		t.Errorf("Open() on a full pool returned %#v\n", err)
+	}
+
+	s.Write([]byte("bonjour"))
+	<-s.Results()
+	stats := p.Stats()
+	if len(stats) != 1 || stats[0].BytesWritten != 7 || stats[0].FinalResults != 1 || stats[0].Model != "en-US_NarrowbandModel" {
+		t.Errorf("Stats() returned %#v\n", stats)
+	}
+	s.Stop()
+	<-s.Done()
+
+	s, err = p.Open(context.Background(), "fr-FR", "audio/l16;rate=16000", RecognizeOptions{})
+	if err != nil {
+		t.Fatalf("Open() after release failed %#v\n", err)
+	}
+	if model := <-models; model != "fr-FR_BroadbandModel" {
+		t.Errorf("fr-FR routed to %s\n", model)
+	}
+	s.Abort()
+}
+
+func TestPoolListModelsBackoff(t *testing.T) {
+	var lists int32
+	c, closeService := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/authorization/"):
+			w.Write([]byte("token"))
+		case r.URL.Path == "/v1/models":
+			if atomic.AddInt32(&lists, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"models": [{"name": "fr-FR_BroadbandModel", "language": "fr-FR", "rate": 16000}]}`))
+		default:
+			websocket.Handler(fakeRecognizer).ServeHTTP(w, r)
+		}
+	})
+	defer closeService()
+	p := NewPool([]Client{c}, PoolConfig{})
+	now := time.Unix(1000, 0)
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Open(context.Background(), "fr-FR", "audio/l16;rate=16000", RecognizeOptions{}); err == nil {
+			t.Errorf("Open() succeeded while models could not be listed\n")
+		}
+	}
+	if n := atomic.LoadInt32(&lists); n != 1 {
+		t.Errorf("models listed %d times during the backoff, wanted 1\n", n)
+	}
+
+	now = now.Add(time.Second)
+	s, err := p.Open(context.Background(), "fr-FR", "audio/l16;rate=16000", RecognizeOptions{})
+	if err != nil {
+		t.Fatalf("Open() after the backoff failed %#v\n", err)
+	}
+	s.Abort()
+	if _, err := p.Open(context.Background(), "de-DE", "audio/l16;rate=16000", RecognizeOptions{}); err == nil || atomic.LoadInt32(&lists) != 2 {
+		t.Errorf("Open() of an unlisted language returned %#v after %d listings\n", err, atomic.LoadInt32(&lists))
+	}
+}
+
+func TestSessionObserveResult(t *testing.T) {
+	c, closeService := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/sessions":
+			http.SetCookie(w, &http.Cookie{Name: "SESSIONID", Value: "abc"})
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"session_id": "s1", "recognize": "http://example.com/v1/sessions/s1/recognize"}`))
+		case r.URL.Path == "/v1/sessions/s1/observe_result":
+			if cookie, err := r.Cookie("SESSIONID"); err != nil || cookie.Value != "abc" || r.URL.Query().Get("sequence_id") != "7" {
+				t.Errorf("observe_result called without session cookie or sequence_id: %s\n", r.URL)
+			}
+			w.Write([]byte(`{"results": [{"final": false, "alternatives": [{"transcript": "hel"}]}], "result_index": 0}
+				{"results": [{"final": true, "alternatives": [{"transcript": "hello "}]}], "result_index": 0}`))
+		default:
+			t.Errorf("unexpected request %s %s\n", r.Method, r.URL)
+		}
+	})
+	defer closeService()
+
+	session, err := c.CreateSession("")
+	if err != nil {
+		t.Fatalf("CreateSession() failed %#v\n", err)
+	}
+	stream, err := session.ObserveResult(context.Background(), 7, true)
+	if err != nil {
+		t.Fatalf("ObserveResult() failed %#v\n", err)
+	}
+	defer stream.Close()
+	var transcripts []string
+	for {
+		event, err := stream.Next()
+		if err != nil {
+			break
+		}
+		transcripts = append(transcripts, event.Results[0].Alternatives[0].Transcript)
+	}
+	if strings.Join(transcripts, "|") != "hel|hello " {
+		t.Errorf("ObserveResult() returned %q\n", transcripts)
+	}
+}
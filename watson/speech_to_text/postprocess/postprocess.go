@@ -0,0 +1,159 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postprocess normalizes the transcripts returned by the Watson Speech to Text service, with
+// configurable rules such as converting numbers to digits or masking profanity. Rules operate on words
+// carrying their time alignment, so that processed transcripts remain aligned with the audio.
+package postprocess
+
+import (
+	"math"
+	"strings"
+
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text"
+)
+
+// Word is a word of a transcript
+type Word struct {
+	Text string
+	// Start and end of the word, in seconds; zero if the transcript has no timestamps
+	Start float64
+	End   float64
+	// Confidence of the word; NaN if the transcript has no word confidence
+	Confidence float64
+}
+
+// Rule rewrites the words of a transcript. A rule replacing several words with one (for example, "twenty five"
+// with "25") should use Merge(), so that the new word spans the time of the words it replaces.
+type Rule interface {
+	Apply(words []Word) []Word
+}
+
+// RuleFunc adapts a function to the Rule interface
+type RuleFunc func(words []Word) []Word
+
+func (f RuleFunc) Apply(words []Word) []Word {
+	return f(words)
+}
+
+// Merge returns a word with text, spanning the time of words, with the lowest of their confidences
+func Merge(text string, words []Word) Word {
+	w := Word{Text: text, Confidence: math.NaN()}
+	if len(words) == 0 {
+		return w
+	}
+	w.Start, w.End, w.Confidence = words[0].Start, words[len(words)-1].End, words[0].Confidence
+	for _, word := range words[1:] {
+		if word.Confidence < w.Confidence {
+			w.Confidence = word.Confidence
+		}
+	}
+	return w
+}
+
+// Processor applies rules, in order, to transcripts
+type Processor struct {
+	rules []Rule
+}
+
+// New creates a processor applying rules in order
+func New(rules ...Rule) *Processor {
+	return &Processor{rules: rules}
+}
+
+// Words applies the rules of the processor to words
+func (p *Processor) Words(words []Word) []Word {
+	for _, r := range p.rules {
+		words = r.Apply(words)
+	}
+	return words
+}
+
+// Event processes the best alternative of each final result of event; interim results, which are replaced
+// later, are left untouched.
+func (p *Processor) Event(event speech_to_text.Event) (speech_to_text.Event, error) {
+	results := make([]speech_to_text.Result, len(event.Results))
+	copy(results, event.Results)
+	for i, result := range results {
+		if !result.Final || len(result.Alternatives) == 0 {
+			continue
+		}
+		alternatives := make([]speech_to_text.Alternative, len(result.Alternatives))
+		copy(alternatives, result.Alternatives)
+		best, err := p.Alternative(alternatives[0])
+		if err != nil {
+			return event, err
+		}
+		alternatives[0] = best
+		results[i].Alternatives = alternatives
+	}
+	event.Results = results
+	return event, nil
+}
+
+// Alternative processes the transcript of a, along with its timestamps and word confidences (if any), which
+// are rewritten to match the processed transcript.
+func (p *Processor) Alternative(a speech_to_text.Alternative) (speech_to_text.Alternative, error) {
+	timings, err := a.WordTimings()
+	if err != nil {
+		return a, err
+	}
+	confidences, err := a.WordConfidences()
+	if err != nil {
+		return a, err
+	}
+	var words []Word
+	if len(timings) > 0 {
+		for _, t := range timings {
+			words = append(words, Word{Text: t.Word, Start: t.Start, End: t.End, Confidence: math.NaN()})
+		}
+	} else {
+		for _, text := range strings.Fields(a.Transcript) {
+			words = append(words, Word{Text: text, Confidence: math.NaN()})
+		}
+	}
+	// confidences are only used if they are aligned with the words
+	hasConfidence := len(confidences) > 0 && len(confidences) == len(words)
+	if hasConfidence {
+		for i := range words {
+			words[i].Confidence = confidences[i].Confidence
+		}
+	}
+
+	words = p.Words(words)
+
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	transcript := strings.Join(texts, " ")
+	if strings.HasSuffix(a.Transcript, " ") && len(transcript) > 0 {
+		transcript += " "
+	}
+	a.Transcript = transcript
+	if len(timings) > 0 {
+		a.Timestamps = make([][]interface{}, len(words))
+		for i, w := range words {
+			a.Timestamps[i] = []interface{}{w.Text, w.Start, w.End}
+		}
+	}
+	if hasConfidence {
+		a.WordConfidence = make([][]interface{}, len(words))
+		for i, w := range words {
+			a.WordConfidence[i] = []interface{}{w.Text, w.Confidence}
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson/speech_to_text"
+)
+
+// words returns the words of text, each lasting one second
+func words(text string) []Word {
+	var ws []Word
+	for i, t := range strings.Fields(text) {
+		ws = append(ws, Word{Text: t, Start: float64(i), End: float64(i + 1)})
+	}
+	return ws
+}
+
+func texts(ws []Word) string {
+	var ts []string
+	for _, w := range ws {
+		ts = append(ts, w.Text)
+	}
+	return strings.Join(ts, " ")
+}
+
+func TestRules(t *testing.T) {
+	p := New(Phone(), Dates(), Numbers(), Currency(), Profanity("darn", "dàrn"))
+	tests := []struct {
+		in, want string
+	}{
+		{"call five five five oh one two three", "call 555-0123"},
+		{"call eight hundred five five five oh one two three", "call (800) 555-0123"},
+		{"call eight zero zero five five five oh one two three", "call (800) 555-0123"},
+		{"code five five five oh one two three four", "code five five five oh one two three four"},
+		{"one eight hundred five five five oh one two three", "one 800 555-0123"},
+		{"I owe twenty five dollars and fifty cents", "I owe $25.50"},
+		{"one hundred and three thousand two hundred and five people", "103205 people"},
+		{"one of the two hundred", "one of the 200"},
+		{"in nineteen eighty four", "in 1984"},
+		{"since twenty oh five and in nineteen hundred", "since 2005 and in 1900"},
+		{"the summer of sixty nine", "the summer of 69"},
+		{"in july nineteen sixty nine", "in july 1969"},
+		{"meet at eleven fifteen or twelve thirty", "meet at 11 15 or 12 30"},
+		{"twenty thirty people", "20 30 people"},
+		{"nineteen hundred dollars", "$1900"},
+		{"born july fourth nineteen seventy six", "born July 4, 1976"},
+		{"on march twenty first", "on March 21"},
+		{"twenty five dollars in may", "$25 in may"},
+		{"five dollars for a darn coffee", "$5 for a **** coffee"},
+		{"a dàrn café", "a **** café"},
+	}
+	for _, test := range tests {
+		if got := texts(p.Words(words(test.in))); got != test.want {
+			t.Errorf("processed %q to %q, wanted %q\n", test.in, got, test.want)
+		}
+	}
+}
+
+func TestAlternativeAlignment(t *testing.T) {
+	a := speech_to_text.Alternative{
+		Transcript:     "it costs twenty five dollars ",
+		Timestamps:     [][]interface{}{{"it", 0.1, 0.2}, {"costs", 0.2, 0.6}, {"twenty", 0.7, 1.0}, {"five", 1.0, 1.3}, {"dollars", 1.3, 1.8}},
+		WordConfidence: [][]interface{}{{"it", 0.9}, {"costs", 0.8}, {"twenty", 0.95}, {"five", 0.7}, {"dollars", 0.9}},
+	}
+	got, err := New(Numbers(), Currency()).Alternative(a)
+	if err != nil {
+		t.Errorf("Alternative() failed %#v\n", err)
+		return
+	}
+	if got.Transcript != "it costs $25 " {
+		t.Errorf("Alternative() returned transcript %q\n", got.Transcript)
+	}
+	timings, _ := got.WordTimings()
+	confidences, _ := got.WordConfidences()
+	if len(timings) != 3 || timings[2] != (speech_to_text.WordTiming{Word: "$25", Start: 0.7, End: 1.8}) {
+		t.Errorf("Alternative() returned timestamps %#v\n", timings)
+	}
+	if len(confidences) != 3 || confidences[2].Confidence != 0.7 {
+		t.Errorf("Alternative() returned word confidences %#v\n", confidences)
+	}
+}
@@ -0,0 +1,376 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postprocess
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var units = map[string]int{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+}
+
+var teens = map[string]int{
+	"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+	"seventeen": 17, "eighteen": 18, "nineteen": 19,
+}
+
+var tens = map[string]int{
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var scales = map[string]int{
+	"thousand": 1000, "million": 1000000, "billion": 1000000000,
+}
+
+// number accumulates the words of a spelled out English number
+type number struct {
+	// total holds the completed scales (thousands, millions...), current the part below the last scale
+	total, current int
+	// last is the kind of the last word: "", "unit", "teen", "ten", "hundred" or "scale"
+	last string
+	// scale is the last scale used, which following scales must be below
+	scale int
+	words int
+}
+
+// add adds word to the number, returning false if it cannot continue the number
+func (n *number) add(word string) bool {
+	if v, ok := units[word]; ok {
+		if n.last == "unit" || n.last == "teen" || n.last == "ten" && n.current%10 != 0 {
+			return false
+		}
+		if v == 0 && n.words > 0 {
+			return false
+		}
+		n.current += v
+		n.last = "unit"
+	} else if v, ok := teens[word]; ok {
+		if n.last != "" && n.last != "hundred" && n.last != "scale" {
+			return false
+		}
+		n.current += v
+		n.last = "teen"
+	} else if v, ok := tens[word]; ok {
+		if n.last != "" && n.last != "hundred" && n.last != "scale" {
+			return false
+		}
+		n.current += v
+		n.last = "ten"
+	} else if word == "hundred" {
+		if n.current == 0 || n.current >= 100 || n.last == "hundred" || n.last == "scale" {
+			return false
+		}
+		n.current *= 100
+		n.last = "hundred"
+	} else if v, ok := scales[word]; ok {
+		if n.current == 0 || n.last == "scale" || n.scale != 0 && v >= n.scale {
+			return false
+		}
+		n.total += n.current * v
+		n.current = 0
+		n.scale = v
+		n.last = "scale"
+	} else {
+		return false
+	}
+	n.words++
+	return true
+}
+
+func (n *number) value() int {
+	return n.total + n.current
+}
+
+// Numbers converts spelled out numbers to digits ("two hundred and five" to "205"). Single words below ten are
+// left spelled out, as is customary in prose.
+func Numbers() Rule {
+	return RuleFunc(func(words []Word) []Word {
+		var out []Word
+		for i := 0; i < len(words); {
+			var n number
+			j := i
+			for j < len(words) {
+				text := strings.ToLower(words[j].Text)
+				// "and" continues a number after hundreds or scales, if a number word follows
+				if text == "and" && (n.last == "hundred" || n.last == "scale") && j+1 < len(words) {
+					next := n
+					if next.add(strings.ToLower(words[j+1].Text)) && next.last != "hundred" && next.last != "scale" {
+						j++
+						continue
+					}
+				}
+				if !n.add(text) {
+					break
+				}
+				j++
+			}
+			if n.words == 0 || n.words == 1 && n.value() < 10 {
+				out = append(out, words[i])
+				i++
+				continue
+			}
+			out = append(out, Merge(strconv.Itoa(n.value()), words[i:j]))
+			i = j
+		}
+		return out
+	})
+}
+
+// digitValue returns the value of a word which is a single digit, spelled out or not ("oh" is taken as zero)
+func digitValue(word string) (int, bool) {
+	word = strings.ToLower(word)
+	if v, ok := units[word]; ok {
+		return v, true
+	}
+	if word == "oh" {
+		return 0, true
+	}
+	if len(word) == 1 && word[0] >= '0' && word[0] <= '9' {
+		return int(word[0] - '0'), true
+	}
+	return 0, false
+}
+
+// Phone converts runs of 7 or 10 digits, as phone numbers are dictated, to "555-0123" or "(800) 555-0123". The
+// area code may be spoken as "eight hundred". Runs of any other length are left unchanged. It should be applied
+// before Numbers.
+func Phone() Rule {
+	return RuleFunc(func(words []Word) []Word {
+		var out []Word
+		for i := 0; i < len(words); {
+			j := i
+			var digits []byte
+			for ; j < len(words); j++ {
+				if v, ok := digitValue(words[j].Text); ok {
+					digits = append(digits, byte('0'+v))
+				} else if j == i+1 && digits[0] != '0' && strings.ToLower(words[j].Text) == "hundred" {
+					digits = append(digits, '0', '0')
+				} else {
+					break
+				}
+			}
+			switch len(digits) {
+			case 0:
+				out = append(out, words[i])
+				i++
+				continue
+			case 7:
+				out = append(out, Merge(string(digits[:3])+"-"+string(digits[3:]), words[i:j]))
+			case 10:
+				out = append(out, Merge("("+string(digits[:3])+") "+string(digits[3:6])+"-"+string(digits[6:]), words[i:j]))
+			default:
+				out = append(out, words[i:j]...)
+			}
+			i = j
+		}
+		return out
+	})
+}
+
+var months = map[string]string{
+	"january": "January", "february": "February", "march": "March", "april": "April", "may": "May", "june": "June",
+	"july": "July", "august": "August", "september": "September", "october": "October", "november": "November",
+	"december": "December",
+}
+
+var ordinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9,
+	"tenth": 10, "eleventh": 11, "twelfth": 12, "thirteenth": 13, "fourteenth": 14, "fifteenth": 15,
+	"sixteenth": 16, "seventeenth": 17, "eighteenth": 18, "nineteenth": 19, "twentieth": 20, "thirtieth": 30,
+}
+
+// year parses a year spoken as two pairs of digits ("nineteen eighty four", "twenty oh five" or "nineteen
+// hundred") at the start of words, returning it and the number of words it spans, or 0 words if there is none
+func year(words []Word) (int, int) {
+	if len(words) < 2 {
+		return 0, 0
+	}
+	century, ok := teens[strings.ToLower(words[0].Text)]
+	if !ok || century == 10 {
+		if century, ok = tens[strings.ToLower(words[0].Text)]; !ok || century != 20 {
+			return 0, 0
+		}
+	}
+	unit := 0
+	if len(words) > 2 {
+		unit = units[strings.ToLower(words[2].Text)]
+	}
+	second := strings.ToLower(words[1].Text)
+	if v, ok := teens[second]; ok {
+		return century*100 + v, 2
+	}
+	if v, ok := tens[second]; ok {
+		if unit > 0 {
+			return century*100 + v + unit, 3
+		}
+		return century*100 + v, 2
+	}
+	if second == "oh" && unit > 0 {
+		return century*100 + unit, 3
+	}
+	if second == "hundred" {
+		return century * 100, 2
+	}
+	return 0, 0
+}
+
+// day parses a day of the month spoken as an ordinal ("fourth" or "twenty first") at the start of words,
+// returning it and the number of words it spans, or 0 words if there is none
+func day(words []Word) (int, int) {
+	if len(words) == 0 {
+		return 0, 0
+	}
+	first := strings.ToLower(words[0].Text)
+	if v, ok := ordinals[first]; ok {
+		return v, 1
+	}
+	if v, ok := tens[first]; ok && v <= 30 && len(words) > 1 {
+		if u, ok := ordinals[strings.ToLower(words[1].Text)]; ok && u < 10 && v+u <= 31 {
+			return v + u, 2
+		}
+	}
+	return 0, 0
+}
+
+// yearCues are the words after which pairs of numbers are taken as a year, rather than as a time or a count
+var yearCues = map[string]bool{"in": true, "since": true, "of": true}
+
+// Dates converts days following the name of a month ("july fourth nineteen seventy six" to "July 4, 1976"), and
+// years spoken as pairs of digits after a month or after "in", "since" or "of" ("in nineteen eighty four" to
+// "in 1984"). Elsewhere, pairs such as "eleven fifteen" or "twenty thirty" are more likely times or counts, and
+// are left to Numbers. It should be applied before Numbers, which would otherwise convert the pairs separately.
+func Dates() Rule {
+	return RuleFunc(func(words []Word) []Word {
+		var out []Word
+		for i := 0; i < len(words); {
+			if month, ok := months[strings.ToLower(words[i].Text)]; ok {
+				if d, n := day(words[i+1:]); n > 0 {
+					m := words[i]
+					m.Text = month
+					out = append(out, m)
+					j := i + 1 + n
+					y, k := year(words[j:])
+					text := strconv.Itoa(d)
+					if k > 0 {
+						text += ","
+					}
+					out = append(out, Merge(text, words[i+1:j]))
+					if k > 0 {
+						out = append(out, Merge(strconv.Itoa(y), words[j:j+k]))
+					}
+					i = j + k
+					continue
+				}
+			}
+			if i == 0 {
+				out = append(out, words[i])
+				i++
+				continue
+			}
+			previous := strings.ToLower(words[i-1].Text)
+			if _, ok := months[previous]; !ok && !yearCues[previous] {
+				out = append(out, words[i])
+				i++
+				continue
+			}
+			if y, n := year(words[i:]); n > 0 {
+				out = append(out, Merge(strconv.Itoa(y), words[i:i+n]))
+				i += n
+				continue
+			}
+			out = append(out, words[i])
+			i++
+		}
+		return out
+	})
+}
+
+// amountValue returns the value of a word which is an amount, in digits or a spelled out digit
+func amountValue(word string) (int, bool) {
+	if v, err := strconv.Atoi(word); err == nil && v >= 0 {
+		return v, true
+	}
+	return digitValue(word)
+}
+
+// Currency converts dollar amounts ("25 dollars and 50 cents" to "$25.50", "5 dollars" to "$5"). It should be
+// applied after Numbers.
+func Currency() Rule {
+	return RuleFunc(func(words []Word) []Word {
+		var out []Word
+		for i := 0; i < len(words); i++ {
+			dollars, ok := amountValue(words[i].Text)
+			if !ok || i+1 >= len(words) {
+				out = append(out, words[i])
+				continue
+			}
+			unit := strings.ToLower(words[i+1].Text)
+			switch unit {
+			case "dollar", "dollars":
+				j := i + 2
+				text := "$" + strconv.Itoa(dollars)
+				// "and N cents"
+				if j+2 < len(words) && strings.ToLower(words[j].Text) == "and" && isCents(words[j+2].Text) {
+					if cents, ok := amountValue(words[j+1].Text); ok && cents < 100 {
+						text += "." + twoDigits(cents)
+						j += 3
+					}
+				}
+				out = append(out, Merge(text, words[i:j]))
+				i = j - 1
+			case "cent", "cents":
+				out = append(out, Merge(strconv.Itoa(dollars)+"¢", words[i:i+2]))
+				i++
+			default:
+				out = append(out, words[i])
+			}
+		}
+		return out
+	})
+}
+
+func isCents(word string) bool {
+	word = strings.ToLower(word)
+	return word == "cent" || word == "cents"
+}
+
+func twoDigits(v int) string {
+	if v < 10 {
+		return "0" + strconv.Itoa(v)
+	}
+	return strconv.Itoa(v)
+}
+
+// Profanity masks the given words (compared without regard to case) with asterisks
+func Profanity(profane ...string) Rule {
+	masked := make(map[string]bool)
+	for _, word := range profane {
+		masked[strings.ToLower(word)] = true
+	}
+	return RuleFunc(func(words []Word) []Word {
+		out := make([]Word, len(words))
+		for i, w := range words {
+			if masked[strings.ToLower(w.Text)] {
+				w.Text = strings.Repeat("*", utf8.RuneCountInString(w.Text))
+			}
+			out[i] = w
+		}
+		return out
+	})
+}
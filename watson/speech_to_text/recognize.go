@@ -0,0 +1,159 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecognizeOptions contains the parameters of a recognition request.
+type RecognizeOptions struct {
+	// Model used for recognition (for example, en-US_BroadbandModel); the service default if empty.
+	Model string
+	// Custom language model to use for recognition (see CreateCustomization()); it must be based on Model.
+	CustomizationId string
+	// If true, multiple final results are returned, one for each pause in the audio; otherwise recognition
+	// stops at the first pause.
+	Continuous bool
+	// Maximum number of alternative transcripts to return; the service default (1) if zero.
+	MaxAlternatives int
+	// If true, time alignment is returned for each word.
+	Timestamps bool
+	// If true, a confidence measure is returned for each word.
+	WordConfidence bool
+	// Seconds of silence after which the connection is closed; the service default (30) if zero, and
+	// unlimited if negative.
+	InactivityTimeout int
+	// Keywords to spot in the audio; matches are returned in Result.KeywordsResult (see KeywordSpotter).
+	// KeywordsThreshold must be set as well.
+	Keywords []string
+	// Minimum confidence, between 0 and 1, of keyword matches
+	KeywordsThreshold float64
+	// If positive, alternative hypotheses of words with at least this confidence, between 0 and 1, are returned
+	// in Result.WordAlternatives.
+	WordAlternativesThreshold float64
+	// If true, dates, times, numbers, currency amounts and the like are converted to conventional forms in final
+	// transcripts (US English only).
+	SmartFormatting bool
+	// Whether profanity is censored in transcripts; the service default (true) if nil.
+	ProfanityFilter *bool
+	// If true, the speaker of each word is identified (see Event.SpeakerLabels and Diarizer); implies Timestamps.
+	SpeakerLabels bool
+	// If true, interim (non-final) results are delivered. Only used by websocket sessions.
+	InterimResults bool
+	// Number of events buffered by websocket sessions; 100 if zero.
+	BufferSize int
+	// What websocket sessions do when the event buffer is full; OverflowBlock by default.
+	Overflow OverflowPolicy
+	// Interval between websocket ping frames, keeping idle connections open; no pings are sent if zero.
+	Keepalive time.Duration
+}
+
+// query returns the options as query parameters of the HTTP interface
+func (o RecognizeOptions) query() url.Values {
+	q := url.Values{}
+	for k, v := range o.parameters() {
+		switch v := v.(type) {
+		case string:
+			q.Set(k, v)
+		case bool:
+			q.Set(k, strconv.FormatBool(v))
+		case int:
+			q.Set(k, strconv.Itoa(v))
+		case float64:
+			q.Set(k, strconv.FormatFloat(v, 'g', -1, 64))
+		case []string:
+			q.Set(k, strings.Join(v, ","))
+		}
+	}
+	return q
+}
+
+// startOptions returns the options as fields of a websocket start message. The model is passed on the
+// websocket URL instead.
+func (o RecognizeOptions) startOptions() map[string]interface{} {
+	m := o.parameters()
+	delete(m, "model")
+	m["interim_results"] = o.InterimResults
+	return m
+}
+
+// parameters returns the options which are set, keyed by their name in the API
+func (o RecognizeOptions) parameters() map[string]interface{} {
+	m := make(map[string]interface{})
+	if len(o.Model) > 0 {
+		m["model"] = o.Model
+	}
+	if len(o.CustomizationId) > 0 {
+		m["customization_id"] = o.CustomizationId
+	}
+	if o.Continuous {
+		m["continuous"] = true
+	}
+	if o.MaxAlternatives > 0 {
+		m["max_alternatives"] = o.MaxAlternatives
+	}
+	if o.Timestamps {
+		m["timestamps"] = true
+	}
+	if o.WordConfidence {
+		m["word_confidence"] = true
+	}
+	if len(o.Keywords) > 0 {
+		m["keywords"] = o.Keywords
+		m["keywords_threshold"] = o.KeywordsThreshold
+	}
+	if o.WordAlternativesThreshold > 0 {
+		m["word_alternatives_threshold"] = o.WordAlternativesThreshold
+	}
+	if o.SmartFormatting {
+		m["smart_formatting"] = true
+	}
+	if o.ProfanityFilter != nil {
+		m["profanity_filter"] = *o.ProfanityFilter
+	}
+	if o.SpeakerLabels {
+		m["speaker_labels"] = true
+	}
+	if o.InactivityTimeout != 0 {
+		m["inactivity_timeout"] = o.InactivityTimeout
+	}
+	return m
+}
+
+// Calls 'POST /v1/recognize' to transcribe audio of type content_type (for example, "audio/flac" or
+// "audio/l16; rate=16000"). Unlike OpenRecognizeSession, the whole audio is sent in a single HTTP request,
+// and the results are returned once recognition is complete. Audio whose length is not known in advance,
+// such as an *os.File, is sent using chunked transfer encoding, so it is never buffered in memory.
+func (c Client) Recognize(ctx context.Context, audio io.Reader, content_type string, opts RecognizeOptions) (Event, error) {
+	headers := make(http.Header)
+	headers.Set("Content-Type", content_type)
+	headers.Set("Accept", "application/json")
+	body, err := c.watsonClient.MakeRequestContext(ctx, "POST", c.version+"/recognize?"+opts.query().Encode(), audio, headers)
+	if err != nil {
+		return Event{}, err
+	}
+	var event Event
+	err = json.Unmarshal(body, &event)
+	return event, err
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+// newFakeClient returns a client for a local fake of the service
+func newFakeClient(t *testing.T, handler http.HandlerFunc) (Client, func()) {
+	ts := httptest.NewServer(handler)
+	c, err := NewClient(watson.Config{Credentials: watson.Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"}})
+	if err != nil {
+		t.Fatalf("NewClient() failed %#v\n", err)
+	}
+	return c, ts.Close
+}
+
+func TestRecognizeChunked(t *testing.T) {
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/recognize" || r.URL.Query().Get("model") != "en-US_NarrowbandModel" || r.URL.Query().Get("timestamps") != "true" {
+			t.Errorf("unexpected request %s\n", r.URL)
+		}
+		if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+			t.Errorf("audio sent with transfer encoding %#v, wanted chunked\n", r.TransferEncoding)
+		}
+		audio, _ := ioutil.ReadAll(r.Body)
+		if string(audio) != "RIFF...." {
+			t.Errorf("fake received audio %q\n", audio)
+		}
+		w.Write([]byte(`{"results": [{"final": true, "alternatives": [{"transcript": "hello world ", "confidence": 0.9}]}], "result_index": 0}`))
+	})
+	defer closeServer()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("RIFF"))
+		pw.Write([]byte("...."))
+		pw.Close()
+	}()
+	event, err := c.Recognize(context.Background(), pr, "audio/wav", RecognizeOptions{Model: "en-US_NarrowbandModel", Timestamps: true})
+	if err != nil {
+		t.Errorf("Recognize() failed %#v\n", err)
+		return
+	}
+	if len(event.Results) != 1 || event.Results[0].Alternatives[0].Transcript != "hello world " {
+		t.Errorf("Recognize() returned %#v\n", event)
+	}
+}
@@ -0,0 +1,294 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// RelayConfig configures a RelayHandler
+type RelayConfig struct {
+	// Authenticate identifies the user making a request; a non-nil error rejects the request. Required.
+	Authenticate func(r *http.Request) (user string, err error)
+	// Allow is called for authenticated users; sessions it refuses are rejected with 429 Too Many Requests.
+	// nil allows all sessions.
+	Allow func(user string) bool
+	// CheckOrigin decides whether the page making a request, named by its Origin header, may open sessions;
+	// requests it refuses are rejected with 403 Forbidden. As browsers send cookies with websocket requests of
+	// any page, nil only allows requests from the same host, or without Origin (that is, not from a browser).
+	CheckOrigin func(r *http.Request) bool
+	// Maximum number of concurrent sessions of a user; unlimited if zero.
+	MaxSessionsPerUser int
+	// Sessions are stopped after this duration, once the results of the audio received so far are delivered;
+	// unlimited if zero.
+	MaxSessionLength time.Duration
+	// Buffering and keepalive parameters of the sessions opened with the service (see RecognizeOptions).
+	BufferSize int
+	Overflow   OverflowPolicy
+	Keepalive  time.Duration
+	// Done is called once for every request, when it has been served.
+	Done func(RelayRecord)
+}
+
+// RelayRecord describes a request served by a RelayHandler
+type RelayRecord struct {
+	User       string
+	RemoteAddr string
+	Model      string
+	Start      time.Time
+	Duration   time.Duration
+	// HTTP status of the request; 101 Switching Protocols for sessions which were relayed
+	Status int
+	// Bytes of audio received from the client, and of events sent to it
+	BytesIn  int64
+	BytesOut int64
+	Err      error
+}
+
+// ErrSessionLength is sent to the client, and reported in the RelayRecord, when a session is stopped
+// because of RelayConfig.MaxSessionLength
+var ErrSessionLength = errors.New("maximum session length exceeded")
+
+// RelayHandler is an http.Handler relaying websocket recognition sessions from clients, such as web browsers,
+// to the service, so that clients do not need service credentials. The websocket protocol is the one of the
+// service: the client sends a text message {"action": "start", "content-type": "...", ...options}, then
+// binary messages with audio, and finally {"action": "stop"}; recognition events are sent back as JSON text
+// messages. The model is selected with the "model" query parameter, and a custom model with
+// "customization_id".
+type RelayHandler struct {
+	client Client
+	cfg    RelayConfig
+
+	mu sync.Mutex
+	// active counts the sessions of each user
+	active map[string]int
+}
+
+// NewRelayHandler creates a relay handler opening sessions with c
+func (c Client) NewRelayHandler(cfg RelayConfig) *RelayHandler {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 100
+	}
+	return &RelayHandler{client: c, cfg: cfg, active: make(map[string]int)}
+}
+
+func (h *RelayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	record := RelayRecord{RemoteAddr: r.RemoteAddr, Model: r.URL.Query().Get("model"), Start: time.Now()}
+	defer func() {
+		if h.cfg.Done != nil {
+			record.Duration = time.Since(record.Start)
+			h.cfg.Done(record)
+		}
+	}()
+
+	if h.cfg.Authenticate == nil {
+		record.Status, record.Err = http.StatusInternalServerError, errors.New("no Authenticate hook configured")
+		http.Error(w, record.Err.Error(), record.Status)
+		return
+	}
+	user, err := h.cfg.Authenticate(r)
+	if err != nil {
+		record.Status, record.Err = http.StatusUnauthorized, err
+		http.Error(w, err.Error(), record.Status)
+		return
+	}
+	record.User = user
+	if h.cfg.Allow != nil && !h.cfg.Allow(user) {
+		record.Status, record.Err = http.StatusTooManyRequests, errors.New("quota exceeded")
+		http.Error(w, record.Err.Error(), record.Status)
+		return
+	}
+	if !h.acquire(user) {
+		record.Status, record.Err = http.StatusTooManyRequests, errors.New("too many concurrent sessions")
+		http.Error(w, record.Err.Error(), record.Status)
+		return
+	}
+	defer h.release(user)
+
+	checkOrigin := h.cfg.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if !checkOrigin(r) {
+				record.Status, record.Err = http.StatusForbidden, errors.New("origin not allowed: "+r.Header.Get("Origin"))
+				return record.Err
+			}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			record.Status = http.StatusSwitchingProtocols
+			record.Err = h.relay(conn, r.URL.Query().Get("customization_id"), &record)
+		},
+	}.ServeHTTP(w, r)
+	if record.Status == 0 {
+		// the websocket handshake failed
+		record.Status, record.Err = http.StatusBadRequest, errors.New("websocket handshake failed")
+	}
+}
+
+// sameOrigin returns true if r has no Origin header, or if its Origin has the host of r
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// acquire counts a new session of user, returning false if the user has too many sessions already
+func (h *RelayHandler) acquire(user string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cfg.MaxSessionsPerUser > 0 && h.active[user] >= h.cfg.MaxSessionsPerUser {
+		return false
+	}
+	h.active[user]++
+	return true
+}
+
+func (h *RelayHandler) release(user string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active[user]--
+	if h.active[user] == 0 {
+		delete(h.active, user)
+	}
+}
+
+// frame is a websocket message along with its payload type (text or binary)
+type frame struct {
+	payloadType byte
+	data        []byte
+}
+
+var frameCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		f := v.(*frame)
+		f.payloadType = payloadType
+		f.data = data
+		return nil
+	},
+}
+
+// relay forwards audio received on conn to a new recognition session, and the session events back to conn
+func (h *RelayHandler) relay(conn *websocket.Conn, customizationId string, record *RelayRecord) error {
+	defer conn.Close()
+
+	var start map[string]interface{}
+	err := websocket.JSON.Receive(conn, &start)
+	if err != nil {
+		return err
+	}
+	if start["action"] != "start" {
+		err = errors.New("expected start action")
+		websocket.JSON.Send(conn, map[string]string{"error": err.Error()})
+		return err
+	}
+	contentType, _ := start["content-type"].(string)
+	delete(start, "action")
+	delete(start, "content-type")
+	if len(customizationId) > 0 {
+		start["customization_id"] = customizationId
+	}
+
+	session, err := h.client.dialSession(record.Model, contentType, start, streamConfig{
+		bufferSize: h.cfg.BufferSize,
+		overflow:   h.cfg.Overflow,
+		keepalive:  h.cfg.Keepalive,
+	})
+	if err != nil {
+		websocket.JSON.Send(conn, map[string]string{"error": err.Error()})
+		return err
+	}
+
+	var expired bool
+	var mu sync.Mutex
+	if h.cfg.MaxSessionLength > 0 {
+		timer := time.AfterFunc(h.cfg.MaxSessionLength, func() {
+			mu.Lock()
+			expired = true
+			mu.Unlock()
+			session.Stop()
+		})
+		defer timer.Stop()
+	}
+
+	// record.BytesOut is only touched by this goroutine until it is done
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for event := range session.Results() {
+			b, _ := json.Marshal(event)
+			record.BytesOut += int64(len(b))
+			websocket.Message.Send(conn, string(b))
+		}
+		mu.Lock()
+		if expired {
+			websocket.JSON.Send(conn, map[string]string{"error": ErrSessionLength.Error()})
+		}
+		mu.Unlock()
+		// unblock the reading of audio, if the session ended first
+		conn.Close()
+	}()
+
+	for {
+		var f frame
+		err = frameCodec.Receive(conn, &f)
+		if err != nil {
+			// client went away without sending a stop action, or the session ended
+			session.Abort()
+			break
+		}
+		if f.payloadType == websocket.BinaryFrame {
+			record.BytesIn += int64(len(f.data))
+			_, err = session.Write(f.data)
+			if err != nil {
+				break
+			}
+			continue
+		}
+		var action map[string]interface{}
+		if json.Unmarshal(f.data, &action) == nil && action["action"] == "stop" {
+			session.Stop()
+			break
+		}
+	}
+	<-forwarded
+	mu.Lock()
+	defer mu.Unlock()
+	if expired {
+		return ErrSessionLength
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if session.Err() != ErrSessionAborted {
+		err = session.Err()
+	}
+	return err
+}
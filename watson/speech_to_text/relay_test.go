@@ -0,0 +1,146 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// newRelay returns the URL of a relay to a local fake of the service
+func newRelay(t *testing.T, cfg RelayConfig) (string, func()) {
+	c, closeService := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/authorization/") {
+			w.Write([]byte("token"))
+			return
+		}
+		if r.URL.Query().Get("watson-token") != "token" {
+			t.Errorf("session opened with %s\n", r.URL)
+		}
+		websocket.Handler(fakeRecognizer).ServeHTTP(w, r)
+	})
+	cfg.Authenticate = func(r *http.Request) (string, error) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			return "", errors.New("invalid key")
+		}
+		return "alice", nil
+	}
+	relay := httptest.NewServer(c.NewRelayHandler(cfg))
+	return strings.Replace(relay.URL, "http", "ws", 1), func() {
+		relay.Close()
+		closeService()
+	}
+}
+
+func dialRelay(t *testing.T, url string) *websocket.Conn {
+	config, _ := websocket.NewConfig(url, strings.Replace(url, "ws", "http", 1))
+	config.Header.Set("Authorization", "Bearer key")
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("websocket.DialConfig() failed %#v\n", err)
+	}
+	return conn
+}
+
+func TestRelay(t *testing.T) {
+	records := make(chan RelayRecord, 1)
+	url, closeRelay := newRelay(t, RelayConfig{MaxSessionsPerUser: 1, Done: func(r RelayRecord) { records <- r }})
+	defer closeRelay()
+
+	if _, err := websocket.Dial(url, "", "http://localhost/"); err == nil {
+		t.Errorf("unauthenticated session was accepted\n")
+	}
+	<-records
+
+	conn := dialRelay(t, url)
+	websocket.JSON.Send(conn, map[string]interface{}{"action": "start", "content-type": "audio/l16;rate=16000"})
+	websocket.Message.Send(conn, []byte("hello"))
+
+	// a second session of the same user is refused while the first one is active
+	config, _ := websocket.NewConfig(url, strings.Replace(url, "ws", "http", 1))
+	config.Header.Set("Authorization", "Bearer key")
+	if _, err := websocket.DialConfig(config); err == nil {
+		t.Errorf("session beyond MaxSessionsPerUser was accepted\n")
+	}
+	if r := <-records; r.Status != http.StatusTooManyRequests {
+		t.Errorf("refused session recorded as %#v\n", r)
+	}
+
+	websocket.JSON.Send(conn, map[string]interface{}{"action": "stop"})
+	var event Event
+	if err := websocket.JSON.Receive(conn, &event); err != nil || event.Results[0].Alternatives[0].Transcript != "hello" {
+		t.Errorf("relay sent %#v, %#v\n", event, err)
+	}
+	r := <-records
+	if r.User != "alice" || r.BytesIn != 5 || r.Err != nil {
+		t.Errorf("relayed session recorded as %#v\n", r)
+	}
+}
+
+func TestRelayOrigin(t *testing.T) {
+	records := make(chan RelayRecord, 1)
+	url, closeRelay := newRelay(t, RelayConfig{Done: func(r RelayRecord) { records <- r }})
+	defer closeRelay()
+
+	// pages of other sites are refused, although the browser sends the user's credentials
+	config, _ := websocket.NewConfig(url, "http://evil.example.com/")
+	config.Header.Set("Authorization", "Bearer key")
+	if _, err := websocket.DialConfig(config); err == nil {
+		t.Errorf("session of another origin was accepted\n")
+	}
+	if r := <-records; r.Status != http.StatusForbidden {
+		t.Errorf("session of another origin recorded as %#v\n", r)
+	}
+
+	// a failed upgrade is not recorded as relayed
+	req, _ := http.NewRequest("GET", strings.Replace(url, "ws", "http", 1), nil)
+	req.Header.Set("Authorization", "Bearer key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET failed %#v\n", err)
+	}
+	resp.Body.Close()
+	if r := <-records; r.Status != http.StatusBadRequest || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET answered %d, recorded as %#v\n", resp.StatusCode, r)
+	}
+}
+
+func TestRelayMaxSessionLength(t *testing.T) {
+	url, closeRelay := newRelay(t, RelayConfig{MaxSessionLength: 50 * time.Millisecond})
+	defer closeRelay()
+
+	conn := dialRelay(t, url)
+	websocket.JSON.Send(conn, map[string]interface{}{"action": "start", "content-type": "audio/l16;rate=16000"})
+	websocket.Message.Send(conn, []byte("hello"))
+
+	var messages []string
+	for {
+		var m string
+		if websocket.Message.Receive(conn, &m) != nil {
+			break
+		}
+		messages = append(messages, m)
+	}
+	if len(messages) != 2 || !strings.Contains(messages[0], "hello") || !strings.Contains(messages[1], ErrSessionLength.Error()) {
+		t.Errorf("relay sent %q\n", messages)
+	}
+}
@@ -0,0 +1,433 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"errors"
+	"mime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ResilientOptions contains the parameters of a ResilientSession
+type ResilientOptions struct {
+	RecognizeOptions
+	// Data rate of the audio, used to relate audio offsets to result timestamps. It is derived from the content
+	// type of audio/l16 audio, and must be set otherwise.
+	BytesPerSecond int
+	// Maximum duration of the audio retained for replay after a reconnect; 30 seconds if zero.
+	Buffer time.Duration
+	// Number of consecutive reconnects without any final result, after which the session fails; 3 if zero.
+	MaxRetries int
+	// Delay before reconnecting, doubled for each consecutive reconnect; 500ms if zero.
+	Backoff time.Duration
+}
+
+// ResilientSession is a recognition session which survives the loss of its connection to the service, for
+// example because of a network failure or an inactivity timeout. It retains the audio written since the
+// last final result; when the connection is lost, it reconnects (with a fresh token), replays that audio,
+// and carries on. The result indexes and timestamps of the new connection are re-based, so that consumers
+// see one continuous transcript.
+//
+// As recognition restarts in the middle of the audio, it must have no header: ResilientSession requires
+// raw audio, such as audio/l16. Word timestamps are always requested, to track recognition progress.
+// Error events of the failed connections are not delivered; Err() reports the cause if the session
+// eventually fails.
+type ResilientSession struct {
+	dial           func(reconnect bool) (*RecognizeSession, error)
+	bytesPerSecond int
+	frameSize      int
+	maxBuffer      int
+	maxRetries     int
+	backoff        time.Duration
+
+	results chan Event
+	closing chan struct{}
+	done    chan struct{}
+
+	// writeMu serializes Write and Stop, so that no audio is sent after the stop request. It is held while
+	// writing to the connection, which may block, so it must never be acquired with mu held.
+	writeMu sync.Mutex
+
+	// mu guards the fields below
+	mu  sync.Mutex
+	cur *RecognizeSession
+	// buffer holds the audio written from offset bufferStart on, in bytes since the start of the session
+	buffer      []byte
+	bufferStart int64
+	// committed is the offset up to which final results were received
+	committed int64
+	// timeOffset and indexOffset re-base the results of the current connection
+	timeOffset  float64
+	indexOffset int
+	// nextIndex is the index following the last final result
+	nextIndex  int
+	failures   int
+	reconnects int
+	stopping   bool
+	closed     bool
+	err        error
+}
+
+// NewResilientSession opens a resilient recognition session for audio of type content_type
+func (c Client) NewResilientSession(content_type string, opts ResilientOptions) (*ResilientSession, error) {
+	ro := opts.RecognizeOptions
+	ro.Timestamps = true
+	dial := func(reconnect bool) (*RecognizeSession, error) {
+		if reconnect {
+			// the connection may have been lost because the token expired or was revoked
+			c.tokens.Invalidate()
+		}
+		return c.OpenRecognizeSessionWithOptions(content_type, ro)
+	}
+	return newResilientSession(dial, content_type, opts)
+}
+
+func newResilientSession(dial func(reconnect bool) (*RecognizeSession, error), contentType string, opts ResilientOptions) (*ResilientSession, error) {
+	s := &ResilientSession{
+		dial:           dial,
+		bytesPerSecond: opts.BytesPerSecond,
+		frameSize:      1,
+		maxRetries:     opts.MaxRetries,
+		backoff:        opts.Backoff,
+		results:        make(chan Event, 100),
+		closing:        make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	mediatype, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediatype == "audio/l16" {
+		rate, _ := strconv.Atoi(params["rate"])
+		channels, err := strconv.Atoi(params["channels"])
+		if err != nil {
+			channels = 1
+		}
+		s.frameSize = 2 * channels
+		if s.bytesPerSecond == 0 {
+			s.bytesPerSecond = rate * s.frameSize
+		}
+	}
+	if s.bytesPerSecond <= 0 {
+		return nil, errors.New("resilient sessions require audio/l16 audio, or BytesPerSecond")
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = 30 * time.Second
+	}
+	s.maxBuffer = int(buffer.Seconds() * float64(s.bytesPerSecond))
+	s.maxBuffer -= s.maxBuffer % s.frameSize
+	if s.maxRetries <= 0 {
+		s.maxRetries = 3
+	}
+	if s.backoff <= 0 {
+		s.backoff = 500 * time.Millisecond
+	}
+	s.cur, err = dial(false)
+	if err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+// Results returns the channel on which re-based transcription events are delivered. It is closed when the
+// session ends.
+func (s *ResilientSession) Results() <-chan Event {
+	return s.results
+}
+
+// Done returns a channel which is closed when the session has ended and Results() is closed.
+func (s *ResilientSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the session ended: nil after a successful Stop, ErrSessionAborted after Abort, or
+// the last error if reconnecting failed.
+func (s *ResilientSession) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Reconnects returns the number of times the session reconnected to the service
+func (s *ResilientSession) Reconnects() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reconnects
+}
+
+// Write sends audio to the service, retaining it for replay. Failures to send audio are not reported, as
+// the audio is replayed once the session has reconnected.
+func (s *ResilientSession) Write(p []byte) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	if s.closed || s.stopping {
+		s.mu.Unlock()
+		return 0, errors.New("cannot write to stopped stream")
+	}
+	s.buffer = append(s.buffer, p...)
+	s.trim()
+	cur := s.cur
+	s.mu.Unlock()
+	// the write may block until the service catches up, which must not hold up the delivery of results
+	cur.Write(p)
+	return len(p), nil
+}
+
+// trim drops the buffered audio which was recognized already, or which exceeds the maximum buffer; s.mu must be held
+func (s *ResilientSession) trim() {
+	drop := int(s.committed - s.bufferStart)
+	if excess := len(s.buffer) - s.maxBuffer; excess > drop {
+		drop = excess + (s.frameSize-excess%s.frameSize)%s.frameSize
+	}
+	if drop > len(s.buffer) {
+		drop = len(s.buffer) - len(s.buffer)%s.frameSize
+	}
+	if drop > 0 {
+		s.buffer = s.buffer[drop:]
+		s.bufferStart += int64(drop)
+	}
+}
+
+// Stop gracefully ends the session, once the results of all the audio written have been delivered.
+func (s *ResilientSession) Stop() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	if s.closed || s.stopping {
+		s.mu.Unlock()
+		return errors.New("stream already stopped")
+	}
+	s.stopping = true
+	cur := s.cur
+	s.mu.Unlock()
+	// if stopping fails, the session reconnects, and stops once the audio has been replayed
+	cur.Stop()
+	return nil
+}
+
+// Close is equivalent to Stop, so that the session can be used as an io.WriteCloser.
+func (s *ResilientSession) Close() error {
+	return s.Stop()
+}
+
+// Abort immediately ends the session. Pending results are discarded.
+func (s *ResilientSession) Abort() {
+	s.finish(ErrSessionAborted)
+	s.mu.Lock()
+	cur := s.cur
+	s.mu.Unlock()
+	cur.Abort()
+}
+
+// finish ends the session with err, unless it has ended already
+func (s *ResilientSession) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.closing)
+}
+
+// run relays the events of the current connection, and reconnects when it fails
+func (s *ResilientSession) run() {
+	defer close(s.done)
+	defer close(s.results)
+	for {
+		s.mu.Lock()
+		cur := s.cur
+		s.mu.Unlock()
+		for event := range cur.Results() {
+			if len(event.Error) > 0 {
+				continue
+			}
+			select {
+			case s.results <- s.rebase(event):
+			case <-s.closing:
+				cur.Abort()
+			}
+		}
+		err := cur.Err()
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return
+		}
+		if err == nil || err == ErrSessionAborted {
+			// the service ended recognition after a Stop, or on its own
+			s.finish(err)
+			return
+		}
+		if !s.reconnect(err) {
+			return
+		}
+	}
+}
+
+// reconnect opens a new connection and replays the buffered audio, returning false if the session ended instead
+func (s *ResilientSession) reconnect(cause error) bool {
+	backoff := s.backoff
+	for {
+		s.mu.Lock()
+		s.failures++
+		failures := s.failures
+		s.mu.Unlock()
+		if failures > s.maxRetries {
+			s.finish(cause)
+			return false
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.closing:
+			return false
+		}
+		backoff *= 2
+
+		next, err := s.dial(true)
+		if err != nil {
+			cause = err
+			continue
+		}
+		s.mu.Lock()
+		s.trim()
+		s.timeOffset = float64(s.bufferStart) / float64(s.bytesPerSecond)
+		s.indexOffset = s.nextIndex
+		s.mu.Unlock()
+		err = s.replay(next)
+		if err == errReplayClosed {
+			next.Abort()
+			return false
+		}
+		if err != nil {
+			next.Abort()
+			cause = err
+			continue
+		}
+		return true
+	}
+}
+
+// errReplayClosed is returned by replay if the session ended while the audio was replayed
+var errReplayClosed = errors.New("session ended during replay")
+
+// replay writes the buffered audio to session, without holding s.mu as the writes may block. Audio written in
+// the meantime is buffered too, and replayed in turn; once it has all been sent, session becomes the current
+// connection.
+func (s *ResilientSession) replay(session *RecognizeSession) error {
+	s.mu.Lock()
+	p, sent := s.buffer, s.bufferStart
+	s.mu.Unlock()
+	for {
+		if err := s.send(session, p); err != nil {
+			return err
+		}
+		sent += int64(len(p))
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return errReplayClosed
+		}
+		if end := s.bufferStart + int64(len(s.buffer)); end > sent {
+			// audio written while replaying; anything dropped from the buffer meanwhile is lost
+			start := sent - s.bufferStart
+			if start < 0 {
+				start = 0
+			}
+			p, sent = s.buffer[start:], s.bufferStart+start
+			s.mu.Unlock()
+			continue
+		}
+		s.cur = session
+		s.reconnects++
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping {
+			session.Stop()
+		}
+		return nil
+	}
+}
+
+// send writes p to session, in chunks of a second
+func (s *ResilientSession) send(session *RecognizeSession, p []byte) error {
+	chunk := s.bytesPerSecond - s.bytesPerSecond%s.frameSize
+	for len(p) > 0 {
+		n := chunk
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := session.Write(p[:n]); err != nil {
+			return err
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+// rebase shifts the result indexes and timestamps of an event of the current connection, and records the
+// progress of recognition
+func (s *ResilientSession) rebase(event Event) Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.ResultIndex += s.indexOffset
+	for i, result := range event.Results {
+		for _, alt := range result.Alternatives {
+			for _, t := range alt.Timestamps {
+				for j := 1; j < len(t); j++ {
+					if v, ok := t[j].(float64); ok {
+						t[j] = v + s.timeOffset
+					}
+				}
+			}
+		}
+		for _, matches := range result.KeywordsResult {
+			for j := range matches {
+				matches[j].StartTime += s.timeOffset
+				matches[j].EndTime += s.timeOffset
+			}
+		}
+		for j := range result.WordAlternatives {
+			result.WordAlternatives[j].StartTime += s.timeOffset
+			result.WordAlternatives[j].EndTime += s.timeOffset
+		}
+		if !result.Final {
+			continue
+		}
+		s.failures = 0
+		if index := event.ResultIndex + i + 1; index > s.nextIndex {
+			s.nextIndex = index
+		}
+		if len(result.Alternatives) > 0 {
+			if timings, err := result.Alternatives[0].WordTimings(); err == nil && len(timings) > 0 {
+				end := int64(timings[len(timings)-1].End * float64(s.bytesPerSecond))
+				end -= end % int64(s.frameSize)
+				if end > s.committed {
+					s.committed = end
+				}
+			}
+		}
+	}
+	for i := range event.SpeakerLabels {
+		event.SpeakerLabels[i].From += s.timeOffset
+		event.SpeakerLabels[i].To += s.timeOffset
+	}
+	return event
+}
@@ -0,0 +1,144 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"golang.org/x/net/websocket"
+)
+
+func TestResilientSessionReconnect(t *testing.T) {
+	// audio/l16 at 8000Hz is 16000 bytes per second; each second of audio is recognized as the word it is
+	// filled with. The first connection drops after recognizing two seconds.
+	var connections int32
+	recognized := make(chan bool, 4)
+	ts := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		first := atomic.AddInt32(&connections, 1) == 1
+		seconds := 0
+		for {
+			var b []byte
+			if websocket.Message.Receive(ws, &b) != nil {
+				return
+			}
+			switch {
+			case strings.Contains(string(b), `"action":"start"`):
+				websocket.Message.Send(ws, `{"state": "listening"}`)
+			case strings.Contains(string(b), `"action":"stop"`):
+				websocket.Message.Send(ws, `{"state": "listening"}`)
+			default:
+				if first && seconds == 2 {
+					ws.Close()
+					return
+				}
+				word := string(b[:1])
+				websocket.Message.Send(ws, fmt.Sprintf(`{"results": [{"final": true, "alternatives": [{"transcript": "%s ",
+					"timestamps": [["%s", %d.0, %d.0]]}]}], "result_index": %d}`, word, word, seconds, seconds+1, seconds))
+				seconds++
+				recognized <- true
+			}
+		}
+	}))
+	defer ts.Close()
+	dial := func(reconnect bool) (*RecognizeSession, error) {
+		ws, err := websocket.Dial(strings.Replace(ts.URL, "http", "ws", 1), "", ts.URL)
+		if err != nil {
+			return nil, err
+		}
+		return newSession(ws, "audio/l16;rate=8000", nil, streamConfig{bufferSize: 1}), nil
+	}
+	s, err := newResilientSession(dial, "audio/l16;rate=8000", ResilientOptions{Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("newResilientSession() failed %#v\n", err)
+	}
+
+	go func() {
+		// let the first results arrive before the connection drops, so that only c and d are replayed
+		for _, word := range []string{"a", "b"} {
+			s.Write([]byte(strings.Repeat(word, 16000)))
+			<-recognized
+		}
+		for _, word := range []string{"c", "d"} {
+			s.Write([]byte(strings.Repeat(word, 16000)))
+		}
+		s.Stop()
+	}()
+	var got []string
+	for event := range s.Results() {
+		for i, result := range event.Results {
+			timings, _ := result.Alternatives[0].WordTimings()
+			got = append(got, fmt.Sprintf("%d:%s@%g", event.ResultIndex+i, timings[0].Word, timings[0].Start))
+		}
+	}
+	<-s.Done()
+	if s.Err() != nil || s.Reconnects() != 1 {
+		t.Errorf("session ended with %#v after %d reconnects\n", s.Err(), s.Reconnects())
+	}
+	if want := "0:a@0 1:b@1 2:c@2 3:d@3"; strings.Join(got, " ") != want {
+		t.Errorf("Results() delivered %v, wanted %s\n", got, want)
+	}
+}
+
+func TestResilientSessionToken(t *testing.T) {
+	// the first connection is dropped as soon as audio arrives; the reconnect must not reuse its token
+	var issued int32
+	tokens := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/authorization/") {
+			fmt.Fprintf(w, "token%d", atomic.AddInt32(&issued, 1))
+			return
+		}
+		token := r.URL.Query().Get("watson-token")
+		tokens <- token
+		websocket.Handler(func(ws *websocket.Conn) {
+			if token == "token1" {
+				var b []byte
+				websocket.Message.Receive(ws, &b)
+				websocket.Message.Send(ws, `{"state": "listening"}`)
+				websocket.Message.Receive(ws, &b)
+				ws.Close()
+				return
+			}
+			fakeRecognizer(ws)
+		}).ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c, err := NewClient(watson.Config{Credentials: watson.Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"}})
+	if err != nil {
+		t.Fatalf("NewClient() failed %#v\n", err)
+	}
+
+	s, err := c.NewResilientSession("audio/l16;rate=8000", ResilientOptions{Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewResilientSession() failed %#v\n", err)
+	}
+	s.Write(make([]byte, 16000))
+	if token := <-tokens; token != "token1" {
+		t.Errorf("first connection used token %q\n", token)
+	}
+	if token := <-tokens; token != "token2" {
+		t.Errorf("reconnect used token %q, wanted a fresh one\n", token)
+	}
+	s.Abort()
+	<-s.Done()
+}
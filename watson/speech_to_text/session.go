@@ -0,0 +1,468 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// RecognizeState is the lifecycle state of a RecognizeSession.
+type RecognizeState int
+
+const (
+	// StateStarting: connected to the service, but no audio has been written yet
+	StateStarting RecognizeState = iota
+	// StateListening: recognition has been started and audio is being accepted
+	StateListening
+	// StateStopping: Stop has been called; the session is waiting for the final results
+	StateStopping
+	// StateClosed: the session has ended, successfully or not (see Err)
+	StateClosed
+)
+
+func (s RecognizeState) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateListening:
+		return "listening"
+	case StateStopping:
+		return "stopping"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// ErrSessionAborted is reported by RecognizeSession.Err after a session was ended with Abort.
+var ErrSessionAborted = errors.New("recognize session aborted")
+
+// ErrOverflow is reported by RecognizeSession.Err after a session using OverflowError was ended because
+// results were not consumed fast enough.
+var ErrOverflow = errors.New("recognize session results overflowed")
+
+// TimeoutError is reported by RecognizeSession.Err, and set in the final Event, when the service ends a
+// session because of a timeout.
+type TimeoutError struct {
+	// Inactivity is true if no speech was detected for the inactivity timeout (see
+	// RecognizeOptions.InactivityTimeout); otherwise, no audio was received for the session timeout.
+	Inactivity bool
+	// The error message of the service
+	Message string
+}
+
+func (e *TimeoutError) Error() string {
+	return e.Message
+}
+
+// Timeout returns true, so that TimeoutError can be checked like a net.Error
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// serviceError returns the error corresponding to an error event of the service
+func serviceError(message string) error {
+	switch {
+	case strings.HasPrefix(message, "No speech detected"):
+		return &TimeoutError{Inactivity: true, Message: message}
+	case strings.HasPrefix(message, "Session timed out"):
+		return &TimeoutError{Message: message}
+	}
+	return errors.New(message)
+}
+
+// OverflowPolicy tells what a session does with results which are not consumed fast enough
+type OverflowPolicy int
+
+const (
+	// OverflowBlock: reading from the service stops until results are consumed
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropInterim: interim results are dropped when the buffer is full; final results block
+	OverflowDropInterim
+	// OverflowError: the session ends with ErrOverflow when the buffer is full
+	OverflowError
+)
+
+// streamConfig holds the client-side parameters of a session
+type streamConfig struct {
+	bufferSize int
+	overflow   OverflowPolicy
+	keepalive  time.Duration
+	// ticks, if set, replaces the keepalive ticker; used by tests to send pings at chosen points
+	ticks <-chan time.Time
+	// observe, if set, is called with every event received from the service
+	observe func(Event)
+}
+
+// RecognizeSession is a recognition request over the speech-to-text websocket interface. Audio is written
+// to the session, and transcription events are received from Results(). A session is safe for use by
+// multiple goroutines; typically one goroutine writes audio while another consumes results.
+type RecognizeSession struct {
+	ws          *websocket.Conn
+	contentType string
+	options     map[string]interface{}
+	// interimResults tells whether non-final results are delivered to the caller
+	interimResults bool
+
+	results  chan Event
+	overflow OverflowPolicy
+	observe  func(Event)
+	// closing is closed as soon as the session ends; done once results have been closed as well
+	closing chan struct{}
+	done    chan struct{}
+
+	// mu guards the fields below, and serializes writes to ws
+	mu    sync.Mutex
+	state RecognizeState
+	// acked is set once the service acknowledged the start of recognition
+	acked   bool
+	dropped int
+	err     error
+}
+
+// OpenRecognizeSession opens a websocket to the speech-to-text API. Recognition starts when audio is first
+// written to the returned session, using model (the service default if empty), audio of type content_type,
+// and 'options' as additional parameters of the start message. A "customization_id" option selects a custom
+// language model, and is passed on the websocket URL rather than in the start message. Not to be confused
+// with CreateSession, which creates a stateful HTTP session. More information available at:
+// http://www.ibm.com/smarterplanet/us/en/ibmwatson/developercloud/doc/speech-to-text/websockets.shtml#WSstart
+func (c Client) OpenRecognizeSession(model string, content_type string, options map[string]interface{}) (*RecognizeSession, error) {
+	return c.dialSession(model, content_type, options, streamConfig{bufferSize: 100})
+}
+
+// OpenRecognizeSessionWithOptions is like OpenRecognizeSession, taking the model and start parameters from
+// opts, as well as the buffering and keepalive parameters of the session.
+func (c Client) OpenRecognizeSessionWithOptions(content_type string, opts RecognizeOptions) (*RecognizeSession, error) {
+	cfg := streamConfig{bufferSize: opts.BufferSize, overflow: opts.Overflow, keepalive: opts.Keepalive}
+	if cfg.bufferSize <= 0 {
+		cfg.bufferSize = 100
+	}
+	return c.dialSession(opts.Model, content_type, opts.startOptions(), cfg)
+}
+
+func (c Client) dialSession(model string, content_type string, options map[string]interface{}, cfg streamConfig) (*RecognizeSession, error) {
+	// in dry-run mode, no token is requested; it is masked in the rendered command anyway
+	var token string
+	if c.watsonClient.DryRun == nil {
+		var err error
+		token, err = c.tokens.Token()
+		if err != nil {
+			return nil, errors.New("failed to acquire auth token: " + err.Error())
+		}
+	}
+	u, err := url.Parse(c.watsonClient.Creds.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "ws"
+	} else {
+		u.Scheme = "wss"
+	}
+	q := url.Values{}
+	q.Set("watson-token", token)
+	if len(model) > 0 {
+		q.Set("model", model)
+	}
+	if customization_id, ok := options["customization_id"].(string); ok {
+		q.Set("customization_id", customization_id)
+		start := make(map[string]interface{}, len(options))
+		for k, v := range options {
+			if k != "customization_id" {
+				start[k] = v
+			}
+		}
+		options = start
+	}
+	u.RawQuery = q.Encode()
+	u.Path += c.version + "/recognize"
+	if c.watsonClient.DryRun != nil {
+		return nil, c.watsonClient.DryRunWebsocket(u)
+	}
+
+	origin, err := url.Parse(c.watsonClient.Creds.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &websocket.Config{
+		Location: u,
+		Origin:   origin,
+		Version:  websocket.ProtocolVersionHybi13,
+		Header:   c.watsonClient.Headers,
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		// the token may have been revoked or expired; request a new one next time
+		c.tokens.Invalidate()
+		return nil, errors.New("error dialing websocket: " + err.Error())
+	}
+	return newSession(ws, content_type, options, cfg), nil
+}
+
+func newSession(ws *websocket.Conn, contentType string, options map[string]interface{}, cfg streamConfig) *RecognizeSession {
+	s := &RecognizeSession{
+		ws:          ws,
+		contentType: contentType,
+		options:     options,
+		results:     make(chan Event, cfg.bufferSize),
+		overflow:    cfg.overflow,
+		observe:     cfg.observe,
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if interim, present := options["interim_results"]; present {
+		if interimResults, ok := interim.(bool); ok {
+			s.interimResults = interimResults
+		}
+	}
+	go s.readReplies()
+	if cfg.ticks != nil {
+		go s.keepalive(cfg.ticks)
+	} else if cfg.keepalive > 0 {
+		ticker := time.NewTicker(cfg.keepalive)
+		go func() {
+			s.keepalive(ticker.C)
+			ticker.Stop()
+		}()
+	}
+	return s
+}
+
+// Results returns the channel on which transcription events are delivered. It is closed when the session ends.
+func (s *RecognizeSession) Results() <-chan Event {
+	return s.results
+}
+
+// Done returns a channel which is closed once the session has ended and Results has been closed.
+func (s *RecognizeSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error which ended the session, if any. It should be called once Done is closed.
+func (s *RecognizeSession) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// State returns the current lifecycle state of the session.
+func (s *RecognizeSession) State() RecognizeState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Write sends audio to the service, starting recognition on the first call.
+func (s *RecognizeSession) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case StateStopping, StateClosed:
+		return 0, errors.New("cannot write to stopped stream")
+	case StateStarting:
+		err := s.start()
+		if err != nil {
+			return 0, err
+		}
+	}
+	err := websocket.Message.Send(s.ws, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// start sends the start message; s.mu must be held
+func (s *RecognizeSession) start() error {
+	m := make(map[string]interface{})
+	for k, v := range s.options {
+		m[k] = v
+	}
+	m["action"] = "start"
+	m["content-type"] = s.contentType
+	// interim results are always requested, to receive timely updates; they are filtered in readReplies
+	m["interim_results"] = true
+	err := websocket.JSON.Send(s.ws, m)
+	if err != nil {
+		return err
+	}
+	s.state = StateListening
+	return nil
+}
+
+// Stop gracefully ends the session: the service is told that no more audio follows, and the session ends
+// once it has delivered the final results.
+func (s *RecognizeSession) Stop() error {
+	s.mu.Lock()
+	switch s.state {
+	case StateStopping, StateClosed:
+		s.mu.Unlock()
+		return errors.New("stream already stopped")
+	case StateStarting:
+		// recognition never started, so there is nothing to wait for
+		s.mu.Unlock()
+		s.finish(nil)
+		return nil
+	}
+	s.state = StateStopping
+	err := websocket.JSON.Send(s.ws, map[string]interface{}{"action": "stop"})
+	s.mu.Unlock()
+	if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+// Close is equivalent to Stop, so that the session can be used as an io.WriteCloser.
+func (s *RecognizeSession) Close() error {
+	return s.Stop()
+}
+
+// Abort immediately ends the session, closing the connection to the service. Pending results are discarded.
+func (s *RecognizeSession) Abort() {
+	s.finish(ErrSessionAborted)
+}
+
+// finish moves the session to StateClosed, recording err as the cause unless one was recorded already,
+// and closes the connection. It is safe to call more than once.
+func (s *RecognizeSession) finish(err error) {
+	s.mu.Lock()
+	if s.state == StateClosed {
+		s.mu.Unlock()
+		return
+	}
+	s.state = StateClosed
+	s.err = err
+	s.mu.Unlock()
+	close(s.closing)
+	s.ws.Close()
+}
+
+func (s *RecognizeSession) readReplies() {
+	defer close(s.done)
+	defer close(s.results)
+	for {
+		// read generic JSON
+		var b []byte
+		err := websocket.Message.Receive(s.ws, &b)
+		if err != nil {
+			// after a local Stop/Abort, the connection being closed is expected
+			s.finish(err)
+			return
+		}
+		// try to unmarshal it as a `state` reply
+		var state StateReply
+		err = json.Unmarshal(b, &state)
+		if err == nil && len(state.State) > 0 {
+			s.mu.Lock()
+			acked := s.acked
+			s.acked = true
+			stopping := s.state == StateStopping
+			s.mu.Unlock()
+			// the first state reply acknowledges the start; the next one, after a stop, ends recognition
+			if acked && stopping {
+				s.finish(nil)
+				return
+			}
+			continue
+		}
+		// next, try to unmarshal it as an `Event`
+		var event Event
+		err = json.Unmarshal(b, &event)
+		if err != nil {
+			continue
+		}
+		if s.observe != nil {
+			s.observe(event)
+		}
+		if len(event.Error) > 0 {
+			err := serviceError(event.Error)
+			event.Timeout, _ = err.(*TimeoutError)
+			s.deliver(event)
+			s.finish(err)
+			return
+		}
+		if s.interimResults == false && len(event.Results) > 0 && event.Results[0].Final == false {
+			continue
+		}
+		if !s.deliver(event) {
+			return
+		}
+	}
+}
+
+// deliver pushes event to the results channel, unless the session is closed first
+func (s *RecognizeSession) deliver(event Event) bool {
+	switch {
+	case s.overflow == OverflowDropInterim && len(event.Results) > 0 && !event.Results[len(event.Results)-1].Final:
+		select {
+		case s.results <- event:
+		default:
+			s.mu.Lock()
+			s.dropped++
+			s.mu.Unlock()
+		}
+		return true
+	case s.overflow == OverflowError && len(event.Error) == 0:
+		select {
+		case s.results <- event:
+			return true
+		default:
+			s.finish(ErrOverflow)
+			return false
+		}
+	}
+	select {
+	case s.results <- event:
+		return true
+	case <-s.closing:
+		return false
+	}
+}
+
+// Dropped returns the number of interim results dropped by the OverflowDropInterim policy
+func (s *RecognizeSession) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// keepalive sends a ping frame on every tick, so that idle connections are not closed by proxies
+func (s *RecognizeSession) keepalive(ticks <-chan time.Time) {
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticks:
+		}
+		s.mu.Lock()
+		// ws.PayloadType is only used by ws.Write, which is only called here
+		s.ws.PayloadType = websocket.PingFrame
+		_, err := s.ws.Write(nil)
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,246 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"golang.org/x/net/websocket"
+)
+
+// fakeRecognizer mimics the websocket protocol of the service: each audio message is answered with a final
+// result carrying the audio as transcript, and a stop action with a closing state reply.
+func fakeRecognizer(ws *websocket.Conn) {
+	for {
+		var b []byte
+		if websocket.Message.Receive(ws, &b) != nil {
+			return
+		}
+		switch {
+		case strings.Contains(string(b), `"action":"start"`):
+			websocket.Message.Send(ws, `{"state": "listening"}`)
+		case strings.Contains(string(b), `"action":"stop"`):
+			websocket.Message.Send(ws, `{"state": "listening"}`)
+		default:
+			websocket.JSON.Send(ws, Event{Results: []Result{{Final: true, Alternatives: []Alternative{{Transcript: string(b)}}}}})
+		}
+	}
+}
+
+func dialFake(t *testing.T, handler websocket.Handler) (*websocket.Conn, func()) {
+	ts := httptest.NewServer(handler)
+	ws, err := websocket.Dial(strings.Replace(ts.URL, "http", "ws", 1), "", ts.URL)
+	if err != nil {
+		t.Fatalf("websocket.Dial() failed %#v\n", err)
+	}
+	return ws, ts.Close
+}
+
+func TestSessionStop(t *testing.T) {
+	ws, closeServer := dialFake(t, fakeRecognizer)
+	defer closeServer()
+	s := newSession(ws, "audio/l16;rate=16000", nil, streamConfig{bufferSize: 1})
+
+	go func() {
+		s.Write([]byte("hello"))
+		s.Write([]byte("world"))
+		s.Stop()
+	}()
+	var transcripts []string
+	for event := range s.Results() {
+		transcripts = append(transcripts, event.Results[0].Alternatives[0].Transcript)
+	}
+	<-s.Done()
+	if s.Err() != nil {
+		t.Errorf("Err() after Stop() returned %#v, wanted nil\n", s.Err())
+	}
+	if strings.Join(transcripts, " ") != "hello world" {
+		t.Errorf("Results() delivered %#v, wanted [hello world]\n", transcripts)
+	}
+	if s.State() != StateClosed {
+		t.Errorf("State() after Stop() is %s, wanted %s\n", s.State(), StateClosed)
+	}
+	if _, err := s.Write([]byte("late")); err == nil {
+		t.Errorf("Write() after Stop() succeeded\n")
+	}
+}
+
+func TestSessionAbort(t *testing.T) {
+	ws, closeServer := dialFake(t, fakeRecognizer)
+	defer closeServer()
+	s := newSession(ws, "audio/l16;rate=16000", nil, streamConfig{bufferSize: 1})
+
+	// nobody consumes results, so the session blocks delivering them until aborted
+	for i := 0; i < 3; i++ {
+		s.Write([]byte("audio"))
+	}
+	s.Abort()
+	<-s.Done()
+	if s.Err() != ErrSessionAborted {
+		t.Errorf("Err() after Abort() returned %#v, wanted ErrSessionAborted\n", s.Err())
+	}
+}
+
+func TestSessionServiceError(t *testing.T) {
+	ws, closeServer := dialFake(t, func(ws *websocket.Conn) {
+		websocket.Message.Send(ws, `{"error": "unable to transcode data stream"}`)
+	})
+	defer closeServer()
+	s := newSession(ws, "audio/wav", nil, streamConfig{bufferSize: 1})
+
+	event, ok := <-s.Results()
+	if !ok || event.Error != "unable to transcode data stream" {
+		t.Errorf("Results() delivered %#v, wanted error event\n", event)
+	}
+	<-s.Done()
+	if s.Err() == nil || s.Err().Error() != "unable to transcode data stream" {
+		t.Errorf("Err() returned %#v, wanted service error\n", s.Err())
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	ws, closeServer := dialFake(t, func(ws *websocket.Conn) {
+		websocket.Message.Send(ws, `{"error": "No speech detected for 5s."}`)
+	})
+	defer closeServer()
+	s := newSession(ws, "audio/wav", nil, streamConfig{bufferSize: 1})
+
+	event := <-s.Results()
+	<-s.Done()
+	timeout, ok := s.Err().(*TimeoutError)
+	if !ok || !timeout.Inactivity || event.Timeout != timeout {
+		t.Errorf("session ended with %#v, event %#v; wanted inactivity timeout\n", s.Err(), event)
+	}
+}
+
+func TestSessionOverflow(t *testing.T) {
+	interim := `{"results": [{"final": false, "alternatives": [{"transcript": "hel"}]}], "result_index": 0}`
+	final := `{"results": [{"final": true, "alternatives": [{"transcript": "hello "}]}], "result_index": 0}`
+	fake := func(ws *websocket.Conn) {
+		for _, m := range []string{interim, interim, interim, final} {
+			websocket.Message.Send(ws, m)
+		}
+		// keep the connection open until the client closes it
+		var b []byte
+		websocket.Message.Receive(ws, &b)
+	}
+
+	ws, closeServer := dialFake(t, fake)
+	defer closeServer()
+	// events are observed before being delivered, so once the final result is observed the interim results
+	// in between have been dropped, and the final one is waiting for room in the buffer
+	observed := make(chan Event, 4)
+	s := newSession(ws, "audio/wav", map[string]interface{}{"interim_results": true},
+		streamConfig{bufferSize: 1, overflow: OverflowDropInterim, observe: func(e Event) { observed <- e }})
+	for i := 0; i < 4; i++ {
+		<-observed
+	}
+	first, second := <-s.Results(), <-s.Results()
+	if first.Results[0].Final || !second.Results[0].Final || s.Dropped() != 2 {
+		t.Errorf("OverflowDropInterim delivered %#v, %#v and dropped %d\n", first, second, s.Dropped())
+	}
+	s.Abort()
+
+	ws, closeServer = dialFake(t, fake)
+	defer closeServer()
+	s = newSession(ws, "audio/wav", map[string]interface{}{"interim_results": true}, streamConfig{bufferSize: 1, overflow: OverflowError})
+	<-s.Done()
+	if s.Err() != ErrOverflow {
+		t.Errorf("OverflowError session ended with %#v\n", s.Err())
+	}
+}
+
+func TestSessionKeepalive(t *testing.T) {
+	ws, closeServer := dialFake(t, fakeRecognizer)
+	defer closeServer()
+	ticks := make(chan time.Time)
+	s := newSession(ws, "audio/l16;rate=16000", nil, streamConfig{bufferSize: 1, keepalive: time.Second, ticks: ticks})
+
+	// pings are interleaved with audio and control messages; a tick is only received once the previous
+	// ping has been written
+	ticks <- time.Now()
+	ticks <- time.Now()
+	s.Write([]byte("hello"))
+	ticks <- time.Now()
+	ticks <- time.Now()
+	go s.Stop()
+	for event := range s.Results() {
+		if event.Results[0].Alternatives[0].Transcript != "hello" {
+			t.Errorf("Results() delivered %#v\n", event)
+		}
+	}
+	if s.Err() != nil {
+		t.Errorf("Err() returned %#v\n", s.Err())
+	}
+}
+
+func TestSessionHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/authorization/") {
+			w.Write([]byte("token"))
+			return
+		}
+		headers <- r.Header
+		websocket.Handler(fakeRecognizer).ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c, err := NewClient(watson.Config{
+		Credentials:    watson.Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"},
+		LearningOptOut: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() failed %#v\n", err)
+	}
+
+	_, w, err := c.WithHeaders(http.Header{"X-Correlation-Id": {"abc"}}).NewStream("", "audio/wav", nil)
+	if err != nil {
+		t.Errorf("NewStream() failed %#v\n", err)
+		return
+	}
+	defer w.Close()
+	h := <-headers
+	if h.Get(watson.LearningOptOutHeader) != "true" || h.Get("X-Correlation-Id") != "abc" {
+		t.Errorf("NewStream() sent headers %#v\n", h)
+	}
+}
+
+func TestSessionDryRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent in dry-run mode: %s\n", r.URL)
+	}))
+	defer ts.Close()
+	var out bytes.Buffer
+	c, err := NewClient(watson.Config{
+		Credentials: watson.Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"},
+		DryRun:      &out,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() failed %#v\n", err)
+	}
+	if _, err := c.OpenRecognizeSession("en-US_BroadbandModel", "audio/wav", nil); err != watson.ErrDryRun {
+		t.Errorf("OpenRecognizeSession() in dry-run mode returned %#v\n", err)
+	}
+	if !strings.Contains(out.String(), "/v1/recognize?model=en-US_BroadbandModel&watson-token=****") {
+		t.Errorf("dry-run output %q\n", out.String())
+	}
+}
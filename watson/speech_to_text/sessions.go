@@ -0,0 +1,165 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Session is a session of the HTTP sessions interface, which keeps a recognition model loaded for a series of
+// requests. Requests of a session must carry the session cookie set by the service, so they are made
+// through the methods of Session. For websocket recognition, see OpenRecognizeSession instead.
+type Session struct {
+	SessionId string `json:"session_id"`
+	// URI to create a new session with the same model
+	NewSessionURI string `json:"new_session_uri"`
+	// URI for HTTP recognition requests of the session
+	RecognizeURI string `json:"recognize"`
+	// URI for websocket recognition requests of the session
+	RecognizeWSURI string `json:"recognizeWS"`
+	// URI for observing the results of recognition requests of the session
+	ObserveResultURI string `json:"observe_result"`
+
+	client Client
+	cookie string
+}
+
+type SessionStatus struct {
+	// The state of the session = ['initialized', 'recognizing', 'closed']; a session which is recognizing
+	// cannot accept another recognition request.
+	State string `json:"state"`
+	// URI of the model of the session
+	Model          string `json:"model"`
+	RecognizeURI   string `json:"recognize"`
+	RecognizeWSURI string `json:"recognizeWS"`
+	// URI for observing results
+	ObserveResultURI string `json:"observe_result"`
+}
+
+// Calls 'POST /v1/sessions' to create a session using model (the service default if empty)
+func (c Client) CreateSession(model string) (*Session, error) {
+	path := c.version + "/sessions"
+	if len(model) > 0 {
+		path += "?model=" + url.QueryEscape(model)
+	}
+	resp, err := c.watsonClient.DoRequest(context.Background(), "POST", path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	session := &Session{client: c}
+	err = json.NewDecoder(resp.Body).Decode(session)
+	if err != nil {
+		return nil, err
+	}
+	var cookies []string
+	for _, cookie := range resp.Cookies() {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	session.cookie = strings.Join(cookies, "; ")
+	return session, nil
+}
+
+// header returns the headers of a request of the session
+func (s *Session) header() http.Header {
+	h := make(http.Header)
+	if len(s.cookie) > 0 {
+		h.Set("Cookie", s.cookie)
+	}
+	return h
+}
+
+// Calls 'GET /v1/sessions/{session_id}/recognize' to check whether the session can accept a recognition request
+func (s *Session) Status() (SessionStatus, error) {
+	body, err := s.client.watsonClient.MakeRequest("GET", s.client.version+"/sessions/"+s.SessionId+"/recognize", nil, s.header())
+	if err != nil {
+		return SessionStatus{}, err
+	}
+	var status struct {
+		Session SessionStatus `json:"session"`
+	}
+	err = json.Unmarshal(body, &status)
+	return status.Session, err
+}
+
+// Calls 'POST /v1/sessions/{session_id}/recognize' to transcribe audio of type content_type within the session,
+// like Client.Recognize(). If sequence_id is positive, the results can also be observed with ObserveResult().
+func (s *Session) Recognize(ctx context.Context, audio io.Reader, content_type string, sequence_id int, opts RecognizeOptions) (Event, error) {
+	q := opts.query()
+	// the model is that of the session
+	q.Del("model")
+	if sequence_id > 0 {
+		q.Set("sequence_id", strconv.Itoa(sequence_id))
+	}
+	headers := s.header()
+	headers.Set("Content-Type", content_type)
+	headers.Set("Accept", "application/json")
+	body, err := s.client.watsonClient.MakeRequestContext(ctx, "POST", s.client.version+"/sessions/"+s.SessionId+"/recognize?"+q.Encode(), audio, headers)
+	if err != nil {
+		return Event{}, err
+	}
+	var event Event
+	err = json.Unmarshal(body, &event)
+	return event, err
+}
+
+// ResultStream is a stream of events returned by ObserveResult()
+type ResultStream struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// Next returns the next event of the stream, or io.EOF at its end
+func (r *ResultStream) Next() (Event, error) {
+	var event Event
+	err := r.dec.Decode(&event)
+	return event, err
+}
+
+// Close ends the stream
+func (r *ResultStream) Close() error {
+	return r.body.Close()
+}
+
+// Calls 'GET /v1/sessions/{session_id}/observe_result' to observe the results of the recognition request with
+// the given sequence_id (or of the next request, if sequence_id is not positive) as they are produced.
+// If interim_results is true, interim results are returned as well.
+func (s *Session) ObserveResult(ctx context.Context, sequence_id int, interim_results bool) (*ResultStream, error) {
+	q := url.Values{}
+	if sequence_id > 0 {
+		q.Set("sequence_id", strconv.Itoa(sequence_id))
+	}
+	if interim_results {
+		q.Set("interim_results", "true")
+	}
+	resp, err := s.client.watsonClient.DoRequest(ctx, "GET", s.client.version+"/sessions/"+s.SessionId+"/observe_result?"+q.Encode(), nil, s.header())
+	if err != nil {
+		return nil, err
+	}
+	return &ResultStream{body: resp.Body, dec: json.NewDecoder(resp.Body)}, nil
+}
+
+// Calls 'DELETE /v1/sessions/{session_id}' to delete the session
+func (s *Session) Delete() error {
+	_, err := s.client.watsonClient.MakeRequest("DELETE", s.client.version+"/sessions/"+s.SessionId, nil, s.header())
+	return err
+}
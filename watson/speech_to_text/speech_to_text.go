@@ -18,18 +18,18 @@ package speech_to_text
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
-	"net/url"
+	"net/http"
 
 	"github.com/liviosoares/go-watson-sdk/watson"
 	"github.com/liviosoares/go-watson-sdk/watson/authorization"
-	"golang.org/x/net/websocket"
 )
 
 type Client struct {
 	version      string
 	watsonClient *watson.Client
+	// tokens caches the token used to open websocket sessions
+	tokens *authorization.TokenCache
 }
 
 const defaultMajorVersion = "v1"
@@ -44,14 +44,21 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
 	tts.watsonClient = client
+	tts.tokens = authorization.NewTokenCache(client, 0)
 	return tts, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
+}
+
 type ModelList struct {
 	Models []Model `json:"models"`
 }
@@ -104,6 +111,24 @@ type Event struct {
 	// Array of warning messages about invalid query parameters or JSON fields included with the request. Each element of the array includes a string that describes the nature of the warning followed by an array of invalid argument strings; for example, "Unknown arguments: [u'invalid_arg_1', u'invalid_arg_2']." The request succeeds despite the warnings.
 	Warnings []string `json:"warnings,omitempty"`
 	Error    string   `json:"error,omitempty"`
+	// Speaker labels for the words of the results, if the speaker_labels option is set. When streaming, labels
+	// may be sent in events of their own, and may revise labels previously sent for the same time.
+	SpeakerLabels []SpeakerLabel `json:"speaker_labels,omitempty"`
+	// Set in the final event of a websocket session ended by the service because of a timeout.
+	Timeout *TimeoutError `json:"-"`
+}
+
+type SpeakerLabel struct {
+	// Start of the word, in seconds; matches the start time of the word in the timestamps of the results
+	From float64 `json:"from"`
+	// End of the word, in seconds
+	To float64 `json:"to"`
+	// Numeric identifier of the speaker, starting at 0
+	Speaker int `json:"speaker"`
+	// Confidence score for the identification of the speaker, between 0 and 1
+	Confidence float64 `json:"confidence"`
+	// If true, no further revisions of the labels are sent by the service
+	Final bool `json:"final"`
 }
 
 type Result struct {
@@ -113,7 +138,7 @@ type Result struct {
 	Alternatives []Alternative `json:"alternatives,omitempty"`
 	// Dictionary (or associative array) whose keys are the strings specified for keywords if both that parameter and keywords_threshold
 	// are specified. The array is empty for any keyword for which no matches are found.
-	KeywordsResult KeywordResults `json:"keyword_results,omitempty"`
+	KeywordsResult map[string][]KeywordResult `json:"keywords_result,omitempty"`
 	// List of word alternative hypotheses found for words of the input audio if word_alternatives_threshold is not null.
 	WordAlternatives []WordAlternativeResults `json:"word_alternatives,omitempty"`
 }
@@ -128,33 +153,27 @@ type Alternative struct {
 	Timestamps [][]interface{} `json:"timestamps,omitempty"`
 	// Confidence score for each word of the transcript, between 0 and 1. Each inner list consists of 2 elements: the word and the
 	// confidence of the word. Example: [["hello",0.95],["world",0.866]]. Available only for the best alternative and only in results marked as final.
-	WordConfidence []string `json:"word_confidence,omitempty"`
-}
-
-type KeywordResults struct {
-	// List of each keyword entered via the keywords parameter and an array of its occurrences in the input audio. The keys of the list are the
-	// actual keyword strings. The array that is returned for any keyword is empty if no occurrences of the keyword are spotted in the input.
-	Keyword []KeywordResult `json:"keyword"`
+	WordConfidence [][]interface{} `json:"word_confidence,omitempty"`
 }
 
 type KeywordResult struct {
 	// Specified keyword normalized to the spoken phrase that matched in the audio input. ,
 	NormalizedText string `json:"normalized_text"`
 	// Start time in seconds of the keyword match.
-	StartTime int64 `json:"start_time"`
+	StartTime float64 `json:"start_time"`
 	// End time in seconds of the keyword match.
-	EndTime int64 `json:"end_time"`
+	EndTime float64 `json:"end_time"`
 	// Confidence score of the keyword match.
 	Confidence float64 `json:"confidence"`
 }
 
 type WordAlternativeResults struct {
 	// Start time in seconds of the word that corresponds to the word alternative.
-	StartTime int `json:"start_time"`
+	StartTime float64 `json:"start_time"`
 	// End time in seconds of the word that corresponds to the word alternative.
-	EndTime int `json:"end_time"`
+	EndTime float64 `json:"end_time"`
 	// List of word alternative hypotheses for a word from the input audio.
-	Alternatives []WordAlternativeResult
+	Alternatives []WordAlternativeResult `json:"alternatives"`
 }
 
 type WordAlternativeResult struct {
@@ -172,130 +191,13 @@ type StateReply struct {
 // audio data. Upon the generation of a transcription event, an 'Event' object is pushed into the output channel.
 // 'options' can contain options to be send in the stream initialization; more information available at:
 // http://www.ibm.com/smarterplanet/us/en/ibmwatson/developercloud/doc/speech-to-text/websockets.shtml#WSstart
+//
+// NewStream is a shorthand for OpenRecognizeSession; use the latter to observe errors and control the stream
+// lifecycle.
 func (c Client) NewStream(model string, content_type string, options map[string]interface{}) (<-chan Event, io.WriteCloser, error) {
-	token, err := authorization.GetToken(c.watsonClient.Creds)
-	if err != nil {
-		return nil, nil, errors.New("failed to acquire auth token: " + err.Error())
-	}
-	u, err := url.Parse(c.watsonClient.Creds.Url)
-	if err != nil {
-		return nil, nil, err
-	}
-	u.Scheme = "wss"
-	q := url.Values{}
-	q.Set("watson-token", token)
-	if len(model) > 0 {
-		q.Set("model", model)
-	}
-	u.RawQuery = q.Encode()
-	u.Path += c.version + "/recognize"
-
-	origin, err := url.Parse(c.watsonClient.Creds.Url)
+	s, err := c.OpenRecognizeSession(model, content_type, options)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	config := &websocket.Config{
-		Location: u,
-		Origin:   origin,
-		Version:  websocket.ProtocolVersionHybi13,
-	}
-	ws, err := websocket.DialConfig(config)
-	if err != nil {
-		return nil, nil, errors.New("error dialing websocket: " + err.Error())
-	}
-	output := make(chan Event, 100)
-	s := stream{
-		input:       output,
-		contentType: content_type,
-		ws:          ws,
-		options:     options,
-	}
-	return output, &s, nil
-}
-
-type stream struct {
-	ws             *websocket.Conn
-	contentType    string
-	options        map[string]interface{}
-	interimResults bool
-	input          chan<- Event
-	started        bool
-	stopped        bool
-}
-
-func (s *stream) Write(p []byte) (n int, err error) {
-	if s.stopped {
-		return 0, errors.New("cannot write to stopped stream")
-	}
-	if !s.started {
-		m := make(map[string]interface{})
-		for k, v := range s.options {
-			m[k] = v
-		}
-		m["action"] = "start"
-		m["content-type"] = s.contentType
-		// m["inactivity_timeout"] = -1
-		if interim, present := m["interim_results"]; present {
-			if interimResults, ok := interim.(bool); ok {
-				s.interimResults = interimResults
-			}
-		}
-		m["interim_results"] = true
-		err := websocket.JSON.Send(s.ws, m)
-		if err != nil {
-			return 0, err
-		}
-		s.started = true
-		var state StateReply
-		err = websocket.JSON.Receive(s.ws, &state)
-		if err != nil {
-			return 0, err
-		}
-		go s.readReplies()
-	}
-	err = websocket.Message.Send(s.ws, p)
-	return len(p), err
-
-}
-
-func (s *stream) Close() error {
-	if s.stopped {
-		return errors.New("stream already stopped")
-	}
-	s.stopped = true
-	m := map[string]interface{}{"action": "stop"}
-	return websocket.JSON.Send(s.ws, m)
-}
-
-func (s *stream) readReplies() {
-	for {
-		// read generic JSON
-		var b []byte
-		err := websocket.Message.Receive(s.ws, &b)
-		if err != nil {
-			close(s.input)
-			return
-		}
-		// try to unmarshal it as a `state` reply
-		var state StateReply
-		err = json.Unmarshal(b, &state)
-		if err == nil && len(state.State) > 0 {
-			if s.stopped {
-				close(s.input)
-				return
-			}
-			continue
-		}
-		// next, try to unmarshal it as an `Event`
-		var event Event
-		err = json.Unmarshal(b, &event)
-		if err != nil {
-			continue
-		}
-		if s.interimResults == false && len(event.Results) > 0 && event.Results[0].Final == false {
-			continue
-		}
-		s.input <- event
-	}
+	return s.Results(), s, nil
 }
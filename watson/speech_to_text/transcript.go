@@ -0,0 +1,260 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoTimestamps is returned when captions are requested for results recognized without the "timestamps" option
+var ErrNoTimestamps = errors.New("results have no word timestamps")
+
+// hesitation is the token inserted by the service for hesitations (such as "uhm"); it is left out of transcripts
+const hesitation = "%HESITATION"
+
+// TranscriptOptions controls how a Transcript is split into captions
+type TranscriptOptions struct {
+	// Maximum number of characters in a line of caption; 42 if zero
+	MaxLineLength int
+	// Maximum number of lines in a caption; 2 if zero
+	MaxLines int
+	// Maximum time a caption stays on screen; 6 seconds if zero
+	MaxDuration time.Duration
+}
+
+// Utterance is a final result of recognition, as exported by a Transcript
+type Utterance struct {
+	// Start of the utterance, in seconds (zero without timestamps)
+	Start float64 `json:"start"`
+	// End of the utterance, in seconds (zero without timestamps)
+	End        float64 `json:"end"`
+	Transcript string  `json:"transcript"`
+	Confidence float64 `json:"confidence,omitempty"`
+	// Time alignment of each word, if recognized with the "timestamps" option
+	Timestamps []WordTiming `json:"timestamps,omitempty"`
+	// Confidence of each word, if recognized with the "word_confidence" option
+	WordConfidence []WordConfidence `json:"word_confidence,omitempty"`
+}
+
+// Caption is a timed piece of text, as displayed in subtitles
+type Caption struct {
+	Start time.Duration
+	End   time.Duration
+	// The lines of the caption
+	Lines []string
+}
+
+// Transcript assembles the final results of recognition Events into a transcript, which can be exported as
+// SRT or WebVTT subtitles, timed plain text or JSON. Events are added as they are received, from either
+// Recognize() or a streaming session; interim results are ignored.
+type Transcript struct {
+	opts    TranscriptOptions
+	results []Result
+}
+
+// NewTranscript creates an empty transcript
+func NewTranscript(opts TranscriptOptions) *Transcript {
+	if opts.MaxLineLength <= 0 {
+		opts.MaxLineLength = 42
+	}
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = 2
+	}
+	if opts.MaxDuration <= 0 {
+		opts.MaxDuration = 6 * time.Second
+	}
+	return &Transcript{opts: opts}
+}
+
+// Add adds the final results of event to the transcript. Results replace those previously added with the same
+// result index.
+func (t *Transcript) Add(event Event) {
+	for i, result := range event.Results {
+		if !result.Final {
+			continue
+		}
+		index := event.ResultIndex + i
+		for len(t.results) <= index {
+			t.results = append(t.results, Result{})
+		}
+		t.results[index] = result
+	}
+}
+
+// Utterances returns the utterances of the transcript, in order
+func (t *Transcript) Utterances() ([]Utterance, error) {
+	var utterances []Utterance
+	for _, result := range t.results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		best := result.Alternatives[0]
+		timings, err := best.WordTimings()
+		if err != nil {
+			return nil, err
+		}
+		confidences, err := best.WordConfidences()
+		if err != nil {
+			return nil, err
+		}
+		u := Utterance{
+			Transcript:     dropHesitations(best.Transcript),
+			Confidence:     best.Confidence,
+			Timestamps:     timings,
+			WordConfidence: confidences,
+		}
+		if len(timings) > 0 {
+			u.Start = timings[0].Start
+			u.End = timings[len(timings)-1].End
+		}
+		utterances = append(utterances, u)
+	}
+	return utterances, nil
+}
+
+// Captions splits the transcript into captions. Captions never span utterances, and are at most MaxLines
+// lines of MaxLineLength characters (a longer word is put on a line of its own), shown for at most
+// MaxDuration. It returns ErrNoTimestamps if the results have no word timestamps.
+func (t *Transcript) Captions() ([]Caption, error) {
+	utterances, err := t.Utterances()
+	if err != nil {
+		return nil, err
+	}
+	var captions []Caption
+	for _, u := range utterances {
+		if len(u.Timestamps) == 0 {
+			if len(u.Transcript) > 0 {
+				return nil, ErrNoTimestamps
+			}
+			continue
+		}
+		var c *Caption
+		for _, w := range u.Timestamps {
+			if w.Word == hesitation {
+				continue
+			}
+			start, end := seconds(w.Start), seconds(w.End)
+			if c != nil {
+				last := c.Lines[len(c.Lines)-1]
+				switch {
+				case end-c.Start > t.opts.MaxDuration:
+					c = nil
+				case utf8.RuneCountInString(last)+1+utf8.RuneCountInString(w.Word) <= t.opts.MaxLineLength:
+					c.Lines[len(c.Lines)-1] = last + " " + w.Word
+					c.End = end
+					continue
+				case len(c.Lines) < t.opts.MaxLines:
+					c.Lines = append(c.Lines, w.Word)
+					c.End = end
+					continue
+				default:
+					c = nil
+				}
+			}
+			captions = append(captions, Caption{Start: start, End: end, Lines: []string{w.Word}})
+			c = &captions[len(captions)-1]
+		}
+	}
+	return captions, nil
+}
+
+// WriteSRT writes the transcript as SubRip (.srt) subtitles
+func (t *Transcript) WriteSRT(w io.Writer) error {
+	captions, err := t.Captions()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for i, c := range captions {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1, formatTime(c.Start, ','), formatTime(c.End, ','), strings.Join(c.Lines, "\n"))
+	}
+	return bw.Flush()
+}
+
+// WriteVTT writes the transcript as WebVTT (.vtt) subtitles
+func (t *Transcript) WriteVTT(w io.Writer) error {
+	captions, err := t.Captions()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+	for _, c := range captions {
+		fmt.Fprintf(bw, "%s --> %s\n%s\n\n", formatTime(c.Start, '.'), formatTime(c.End, '.'), strings.Join(c.Lines, "\n"))
+	}
+	return bw.Flush()
+}
+
+// WriteText writes the transcript as plain text, one utterance per line. Each line is prefixed with the start
+// time of the utterance if the results have word timestamps.
+func (t *Transcript) WriteText(w io.Writer) error {
+	utterances, err := t.Utterances()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, u := range utterances {
+		if len(u.Timestamps) > 0 {
+			fmt.Fprintf(bw, "[%s] ", formatTime(seconds(u.Start), '.'))
+		}
+		fmt.Fprintln(bw, u.Transcript)
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the utterances of the transcript as a JSON array
+func (t *Transcript) WriteJSON(w io.Writer) error {
+	utterances, err := t.Utterances()
+	if err != nil {
+		return err
+	}
+	if utterances == nil {
+		utterances = []Utterance{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(utterances)
+}
+
+// dropHesitations removes hesitation tokens and extra spaces from a transcript
+func dropHesitations(transcript string) string {
+	words := strings.Fields(transcript)
+	kept := words[:0]
+	for _, w := range words {
+		if w != hesitation {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// seconds converts a time offset returned by the service to a duration, rounded to the millisecond
+func seconds(s float64) time.Duration {
+	return time.Duration(s*1000+0.5) * time.Millisecond
+}
+
+// formatTime formats d as hh:mm:ss followed by sep and milliseconds, as used by subtitle formats
+func formatTime(d time.Duration, sep byte) string {
+	ms := int64(d / time.Millisecond)
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+const transcriptEvents = `[
+{"results": [{"final": true, "alternatives": [{"transcript": "hello %HESITATION world ", "confidence": 0.9,
+  "timestamps": [["hello", 0.5, 0.9], ["%HESITATION", 0.9, 1.2], ["world", 1.2, 1.75]],
+  "word_confidence": [["hello", 0.95], ["%HESITATION", 0.5], ["world", 0.87]]}]}], "result_index": 0},
+{"results": [{"final": false, "alternatives": [{"transcript": "this is"}]}], "result_index": 1},
+{"results": [{"final": true, "alternatives": [{"transcript": "this is a longer sentence ", "confidence": 0.8,
+  "timestamps": [["this", 3.0, 3.2], ["is", 3.2, 3.4], ["a", 3.4, 3.5], ["longer", 3.5, 3.9], ["sentence", 3.9, 4.5]]}]}], "result_index": 1}
+]`
+
+func newTestTranscript(t *testing.T, opts TranscriptOptions) *Transcript {
+	var events []Event
+	err := json.Unmarshal([]byte(transcriptEvents), &events)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed %#v\n", err)
+	}
+	transcript := NewTranscript(opts)
+	for _, e := range events {
+		transcript.Add(e)
+	}
+	return transcript
+}
+
+func TestWordConfidences(t *testing.T) {
+	var events []Event
+	json.Unmarshal([]byte(transcriptEvents), &events)
+	confidences, err := events[0].Results[0].Alternatives[0].WordConfidences()
+	if err != nil {
+		t.Errorf("WordConfidences() failed %#v\n", err)
+		return
+	}
+	if len(confidences) != 3 || confidences[2] != (WordConfidence{Word: "world", Confidence: 0.87}) {
+		t.Errorf("WordConfidences() returned %#v\n", confidences)
+	}
+	_, err = Alternative{Timestamps: [][]interface{}{{"hello", 0.5}}}.WordTimings()
+	if err == nil {
+		t.Errorf("WordTimings() accepted a malformed timestamp\n")
+	}
+}
+
+func TestTranscriptSRT(t *testing.T) {
+	transcript := newTestTranscript(t, TranscriptOptions{MaxLineLength: 12, MaxLines: 1})
+	var buf bytes.Buffer
+	err := transcript.WriteSRT(&buf)
+	if err != nil {
+		t.Errorf("WriteSRT() failed %#v\n", err)
+		return
+	}
+	want := "1\n00:00:00,500 --> 00:00:01,750\nhello world\n\n" +
+		"2\n00:00:03,000 --> 00:00:03,500\nthis is a\n\n" +
+		"3\n00:00:03,500 --> 00:00:03,900\nlonger\n\n" +
+		"4\n00:00:03,900 --> 00:00:04,500\nsentence\n\n"
+	if buf.String() != want {
+		t.Errorf("WriteSRT() wrote\n%s\nwanted\n%s\n", buf.String(), want)
+	}
+}
+
+func TestTranscriptWrapCharacters(t *testing.T) {
+	transcript := NewTranscript(TranscriptOptions{MaxLineLength: 11, MaxLines: 1})
+	transcript.Add(Event{Results: []Result{{Final: true, Alternatives: []Alternative{{Transcript: "déjà vécu ",
+		Timestamps: [][]interface{}{{"déjà", 0.0, 0.5}, {"vécu", 0.5, 1.0}}}}}}})
+	var buf bytes.Buffer
+	err := transcript.WriteSRT(&buf)
+	if err != nil {
+		t.Errorf("WriteSRT() failed %#v\n", err)
+		return
+	}
+	// "déjà vécu" is 9 characters but 12 bytes long, so it still fits on one line
+	want := "1\n00:00:00,000 --> 00:00:01,000\ndéjà vécu\n\n"
+	if buf.String() != want {
+		t.Errorf("WriteSRT() wrote\n%s\nwanted\n%s\n", buf.String(), want)
+	}
+}
+
+func TestTranscriptVTT(t *testing.T) {
+	transcript := newTestTranscript(t, TranscriptOptions{})
+	var buf bytes.Buffer
+	err := transcript.WriteVTT(&buf)
+	if err != nil {
+		t.Errorf("WriteVTT() failed %#v\n", err)
+		return
+	}
+	want := "WEBVTT\n\n00:00:00.500 --> 00:00:01.750\nhello world\n\n00:00:03.000 --> 00:00:04.500\nthis is a longer sentence\n\n"
+	if buf.String() != want {
+		t.Errorf("WriteVTT() wrote\n%s\nwanted\n%s\n", buf.String(), want)
+	}
+}
+
+func TestTranscriptText(t *testing.T) {
+	transcript := newTestTranscript(t, TranscriptOptions{})
+	var buf bytes.Buffer
+	transcript.WriteText(&buf)
+	want := "[00:00:00.500] hello world\n[00:00:03.000] this is a longer sentence\n"
+	if buf.String() != want {
+		t.Errorf("WriteText() wrote\n%s\nwanted\n%s\n", buf.String(), want)
+	}
+
+	var noTimestamps Transcript
+	noTimestamps.Add(Event{Results: []Result{{Final: true, Alternatives: []Alternative{{Transcript: "hi "}}}}})
+	if _, err := noTimestamps.Captions(); err != ErrNoTimestamps {
+		t.Errorf("Captions() without timestamps returned %#v\n", err)
+	}
+}
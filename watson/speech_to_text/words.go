@@ -0,0 +1,73 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speech_to_text
+
+import (
+	"fmt"
+)
+
+// WordTiming is the time alignment of a word, decoded from Alternative.Timestamps
+type WordTiming struct {
+	Word string `json:"word"`
+	// Start of the word, in seconds from the beginning of the audio
+	Start float64 `json:"start"`
+	// End of the word, in seconds from the beginning of the audio
+	End float64 `json:"end"`
+}
+
+// WordConfidence is the confidence score of a word, decoded from Alternative.WordConfidence
+type WordConfidence struct {
+	Word string `json:"word"`
+	// Confidence score, between 0 and 1
+	Confidence float64 `json:"confidence"`
+}
+
+// WordTimings decodes the time alignments of the words of the transcript. They are only returned by the
+// service for the best alternative, if requested with the "timestamps" option.
+func (a Alternative) WordTimings() ([]WordTiming, error) {
+	timings := make([]WordTiming, 0, len(a.Timestamps))
+	for _, t := range a.Timestamps {
+		if len(t) != 3 {
+			return nil, fmt.Errorf("invalid timestamp %v", t)
+		}
+		word, ok1 := t[0].(string)
+		start, ok2 := t[1].(float64)
+		end, ok3 := t[2].(float64)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("invalid timestamp %v", t)
+		}
+		timings = append(timings, WordTiming{Word: word, Start: start, End: end})
+	}
+	return timings, nil
+}
+
+// WordConfidences decodes the confidence scores of the words of the transcript. They are only returned by the
+// service for the best alternative of final results, if requested with the "word_confidence" option.
+func (a Alternative) WordConfidences() ([]WordConfidence, error) {
+	confidences := make([]WordConfidence, 0, len(a.WordConfidence))
+	for _, c := range a.WordConfidence {
+		if len(c) != 2 {
+			return nil, fmt.Errorf("invalid word confidence %v", c)
+		}
+		word, ok1 := c[0].(string)
+		confidence, ok2 := c[1].(float64)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid word confidence %v", c)
+		}
+		confidences = append(confidences, WordConfidence{Word: word, Confidence: confidence})
+	}
+	return confidences, nil
+}
@@ -0,0 +1,272 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"mime"
+	"strings"
+)
+
+// ConcatAudio joins audio files of type accept (as passed to Synthesize()), such as the results of synthesizing
+// consecutive chunks of text, into one file. The container headers are rewritten for the joined audio: WAV sizes,
+// FLAC stream information and frame numbers, and Ogg page sequence numbers and granule positions. Headerless
+// formats (audio/l16, audio/mulaw, audio/basic and audio/mp3) are simply concatenated. All files must have the same
+// sampling rate and number of channels.
+func ConcatAudio(accept string, parts [][]byte) ([]byte, error) {
+	switch len(parts) {
+	case 0:
+		return nil, errors.New("no audio to concatenate")
+	case 1:
+		return parts[0], nil
+	}
+	media, _, err := mime.ParseMediaType(accept)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(media) {
+	case "audio/wav":
+		return concatWAV(parts)
+	case "audio/flac":
+		return concatFLAC(parts)
+	case "audio/ogg":
+		return concatOgg(parts)
+	case "audio/l16", "audio/mulaw", "audio/basic", "audio/mp3", "audio/mpeg":
+		return bytes.Join(parts, nil), nil
+	}
+	return nil, errors.New("cannot concatenate " + accept + " audio")
+}
+
+// concatWAV joins the data chunks of WAV files, which must have the same format chunk. As the service streams
+// WAV files, their RIFF and data sizes may be unset; the data chunk then extends to the end of the file.
+func concatWAV(parts [][]byte) ([]byte, error) {
+	var format []byte
+	var data bytes.Buffer
+	for _, b := range parts {
+		if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+			return nil, errors.New("invalid WAV file")
+		}
+		var f []byte
+		for b = b[12:]; len(b) >= 8; {
+			id, size := string(b[0:4]), int(binary.LittleEndian.Uint32(b[4:8]))
+			b = b[8:]
+			if id == "data" && (size == 0 || size > len(b)) {
+				size = len(b)
+			}
+			if size > len(b) {
+				return nil, errors.New("truncated WAV " + id + " chunk")
+			}
+			switch id {
+			case "fmt ":
+				f = b[:size]
+			case "data":
+				if f == nil {
+					return nil, errors.New("WAV data chunk before fmt chunk")
+				}
+				data.Write(b[:size])
+			}
+			b = b[size:]
+			if size%2 == 1 && len(b) > 0 {
+				b = b[1:]
+			}
+		}
+		if format == nil {
+			format = f
+		} else if !bytes.Equal(format, f) {
+			return nil, errors.New("WAV files have different formats")
+		}
+	}
+
+	var out bytes.Buffer
+	le := func(n int) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(n))
+		return b
+	}
+	pad := data.Len() % 2
+	out.WriteString("RIFF")
+	out.Write(le(4 + 8 + len(format) + 8 + data.Len() + pad))
+	out.WriteString("WAVEfmt ")
+	out.Write(le(len(format)))
+	out.Write(format)
+	out.WriteString("data")
+	out.Write(le(data.Len()))
+	out.Write(data.Bytes())
+	if pad == 1 {
+		out.WriteByte(0)
+	}
+	return out.Bytes(), nil
+}
+
+// concatOgg joins Ogg Opus or Ogg Vorbis files into one logical stream, keeping the header packets of the
+// first file. Pages are renumbered, and the granule positions of each file are offset by the final granule
+// position of the previous ones. The files must have the same format (channels, sample rate and so on). As
+// packets are not re-encoded, the pre-skip of the Opus files after the first one, the priming of the encoder
+// (typically 6.5ms), is played at each joint rather than skipped.
+func concatOgg(parts [][]byte) ([]byte, error) {
+	var out bytes.Buffer
+	var serial, seq uint32
+	var offset, last int64
+	var format []byte
+	// pages are written once the next one is known, so that the last one can be marked as the end of the stream
+	var pending *oggPage
+	for i, b := range parts {
+		pages, err := oggPages(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(pages) == 0 {
+			continue
+		}
+		// the first packet identifies the codec, and with it the number of header packets
+		first := pages[0].data
+		// the format is described by the identification header, less the fields which may differ between
+		// files of the same format: the Opus pre-skip, and the Vorbis bitrates
+		headers := 0
+		var f []byte
+		switch {
+		case bytes.HasPrefix(first, []byte("OpusHead")) && len(first) >= 19:
+			headers, f = 2, append(append([]byte(nil), first[:10]...), first[12:]...)
+		case bytes.HasPrefix(first, []byte("\x01vorbis")) && len(first) >= 30:
+			headers, f = 3, append(append([]byte(nil), first[:16]...), first[28:]...)
+		default:
+			return nil, errors.New("unsupported Ogg codec")
+		}
+		if i == 0 {
+			serial, format = pages[0].serial, f
+		} else if !bytes.Equal(f, format) {
+			return nil, errors.New("Ogg files have different formats")
+		}
+		packets := 0
+		for _, p := range pages {
+			header := packets < headers
+			for _, lacing := range p.segments {
+				if lacing < 255 {
+					packets++
+				}
+			}
+			if header && i > 0 {
+				continue
+			}
+			p.serial, p.seq = serial, seq
+			p.headerType &^= 0x06 // beginning and end of stream
+			if seq == 0 {
+				p.headerType |= 0x02
+			}
+			seq++
+			if p.granule != -1 && !header {
+				p.granule += offset
+				last = p.granule
+			}
+			if pending != nil {
+				pending.write(&out)
+			}
+			page := p
+			pending = &page
+		}
+		offset = last
+	}
+	if pending != nil {
+		pending.headerType |= 0x04
+		pending.write(&out)
+	}
+	return out.Bytes(), nil
+}
+
+type oggPage struct {
+	headerType byte
+	granule    int64
+	serial     uint32
+	seq        uint32
+	segments   []byte
+	data       []byte
+}
+
+func oggPages(b []byte) ([]oggPage, error) {
+	var pages []oggPage
+	for len(b) > 0 {
+		if len(b) < 27 || string(b[0:4]) != "OggS" || b[4] != 0 {
+			return nil, errors.New("invalid Ogg page")
+		}
+		n := int(b[26])
+		if len(b) < 27+n {
+			return nil, errors.New("truncated Ogg page")
+		}
+		p := oggPage{
+			headerType: b[5],
+			granule:    int64(binary.LittleEndian.Uint64(b[6:14])),
+			serial:     binary.LittleEndian.Uint32(b[14:18]),
+			seq:        binary.LittleEndian.Uint32(b[18:22]),
+			segments:   b[27 : 27+n],
+		}
+		size := 0
+		for _, lacing := range p.segments {
+			size += int(lacing)
+		}
+		if len(b) < 27+n+size {
+			return nil, errors.New("truncated Ogg page")
+		}
+		p.data = b[27+n : 27+n+size]
+		pages = append(pages, p)
+		b = b[27+n+size:]
+	}
+	return pages, nil
+}
+
+func (p oggPage) write(out *bytes.Buffer) {
+	start := out.Len()
+	h := make([]byte, 27)
+	copy(h, "OggS")
+	h[5] = p.headerType
+	binary.LittleEndian.PutUint64(h[6:14], uint64(p.granule))
+	binary.LittleEndian.PutUint32(h[14:18], p.serial)
+	binary.LittleEndian.PutUint32(h[18:22], p.seq)
+	h[26] = byte(len(p.segments))
+	out.Write(h)
+	out.Write(p.segments)
+	out.Write(p.data)
+	b := out.Bytes()[start:]
+	binary.LittleEndian.PutUint32(b[22:26], oggCRC(b))
+}
+
+var oggTable = crcTable32(0x04c11db7)
+
+// oggCRC returns the checksum of an Ogg page (whose checksum field is zero)
+func oggCRC(b []byte) uint32 {
+	var crc uint32
+	for _, c := range b {
+		crc = crc<<8 ^ oggTable[byte(crc>>24)^c]
+	}
+	return crc
+}
+
+func crcTable32(poly uint32) *[256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ poly
+			} else {
+				crc <<= 1
+			}
+		}
+		t[i] = crc
+	}
+	return &t
+}
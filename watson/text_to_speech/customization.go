@@ -0,0 +1,256 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Used to specify a custom voice model in CreateCustomization()
+type CustomizationMetadata struct {
+	// User-defined name for the custom voice model
+	Name string `json:"name"`
+	// Language of the custom voice model (for example, en-US); defaults to en-US if empty
+	Language string `json:"language,omitempty"`
+	// User-defined description of the custom voice model
+	Description string `json:"description,omitempty"`
+}
+
+type CustomizationList struct {
+	Customizations []Customization `json:"customizations"`
+}
+
+type Word struct {
+	// The custom word; ignored by UpdateWord(), which takes the word as argument
+	Word string `json:"word,omitempty"`
+	// Pronunciation of the word, either spelled as it sounds (for example, "I triple E" for "IEEE"), or
+	// as a phoneme element such as the ones returned by PhonemeTranslation()
+	Translation string `json:"translation"`
+}
+
+type WordList struct {
+	Words []Word `json:"words"`
+}
+
+// Calls 'POST /v1/customizations' to create a custom voice model, returning its customization ID
+func (c Client) CreateCustomization(metadata CustomizationMetadata) (string, error) {
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	body, err = c.watsonClient.MakeRequest("POST", c.version+"/customizations", bytes.NewReader(body), headers)
+	if err != nil {
+		return "", err
+	}
+	var customization Customization
+	err = json.Unmarshal(body, &customization)
+	return customization.CustomizationId, err
+}
+
+// Calls 'GET /v1/customizations' to list the custom voice models owned by the service credentials,
+// optionally restricted to a language (if not empty)
+func (c Client) ListCustomizations(language string) (CustomizationList, error) {
+	path := c.version + "/customizations"
+	if len(language) > 0 {
+		path += "?language=" + url.QueryEscape(language)
+	}
+	body, err := c.watsonClient.MakeRequest("GET", path, nil, nil)
+	if err != nil {
+		return CustomizationList{}, err
+	}
+	var customizations CustomizationList
+	err = json.Unmarshal(body, &customizations)
+	return customizations, err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}' to get information on a custom voice model, including its words
+func (c Client) GetCustomization(customization_id string) (Customization, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id, nil, nil)
+	if err != nil {
+		return Customization{}, err
+	}
+	var customization Customization
+	err = json.Unmarshal(body, &customization)
+	return customization, err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}' to update the name and description of a custom voice model
+// (if not empty), and to add or modify words
+func (c Client) UpdateCustomization(customization_id string, name string, description string, words []Word) error {
+	u := struct {
+		Name        string `json:"name,omitempty"`
+		Description string `json:"description,omitempty"`
+		Words       []Word `json:"words,omitempty"`
+	}{Name: name, Description: description, Words: words}
+	body, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	_, err = c.watsonClient.MakeRequest("POST", c.version+"/customizations/"+customization_id, bytes.NewReader(body), headers)
+	return err
+}
+
+// Calls 'DELETE /v1/customizations/{customization_id}' to delete a custom voice model
+func (c Client) DeleteCustomization(customization_id string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/customizations/"+customization_id, nil, nil)
+	return err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/words' to list the words of a custom voice model
+func (c Client) ListWords(customization_id string) (WordList, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id+"/words", nil, nil)
+	if err != nil {
+		return WordList{}, err
+	}
+	var words WordList
+	err = json.Unmarshal(body, &words)
+	return words, err
+}
+
+// Calls 'POST /v1/customizations/{customization_id}/words' to add words to a custom voice model, or to
+// modify existing ones. See AddValidatedWords() to check the translations first.
+func (c Client) AddWords(customization_id string, words []Word) error {
+	body, err := json.Marshal(WordList{Words: words})
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	_, err = c.watsonClient.MakeRequest("POST", c.version+"/customizations/"+customization_id+"/words", bytes.NewReader(body), headers)
+	return err
+}
+
+// Calls 'PUT /v1/customizations/{customization_id}/words/{word}' to add or modify a single word
+func (c Client) UpdateWord(customization_id string, word string, translation string) error {
+	body, err := json.Marshal(Word{Translation: translation})
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	_, err = c.watsonClient.MakeRequest("PUT", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word), bytes.NewReader(body), headers)
+	return err
+}
+
+// Calls 'GET /v1/customizations/{customization_id}/words/{word}' to get the translation of a word
+func (c Client) GetWord(customization_id string, word string) (string, error) {
+	body, err := c.watsonClient.MakeRequest("GET", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	var w Word
+	err = json.Unmarshal(body, &w)
+	return w.Translation, err
+}
+
+// Calls 'DELETE /v1/customizations/{customization_id}/words/{word}' to delete a word
+func (c Client) DeleteWord(customization_id string, word string) error {
+	_, err := c.watsonClient.MakeRequest("DELETE", c.version+"/customizations/"+customization_id+"/words/"+url.PathEscape(word), nil, nil)
+	return err
+}
+
+// PhonemeTranslation returns a translation for a word pronounced as ph, in alphabet 'ipa' or 'spr'
+// (the service's Symbolic Phonetic Representation)
+func PhonemeTranslation(alphabet string, ph string) string {
+	if strings.EqualFold(alphabet, "spr") {
+		alphabet = "ibm"
+	}
+	var b bytes.Buffer
+	b.WriteString(`<phoneme alphabet="` + strings.ToLower(alphabet) + `" ph="`)
+	xml.EscapeText(&b, []byte(ph))
+	b.WriteString(`"></phoneme>`)
+	return b.String()
+}
+
+// PronunciationError is returned by ValidateWord() and AddValidatedWords() for a word whose translation
+// cannot be pronounced
+type PronunciationError struct {
+	Word    string
+	Message string
+}
+
+func (e *PronunciationError) Error() string {
+	return "invalid translation of " + e.Word + ": " + e.Message
+}
+
+// ValidateWord checks that the translation of word can be pronounced by voice, returning its pronunciation
+// in IPA (for phoneme translations, the pronunciation they spell, in their own alphabet). Translations
+// spelled as they sound are checked with GetPronunciation(); phoneme translations must be well formed and use
+// the 'ipa' or 'ibm' alphabet. Invalid translations are returned as a *PronunciationError.
+func (c Client) ValidateWord(voice string, word Word) (string, error) {
+	translation := strings.TrimSpace(word.Translation)
+	if len(translation) == 0 {
+		return "", &PronunciationError{Word: word.Word, Message: "empty translation"}
+	}
+	if strings.HasPrefix(translation, "<") {
+		_, ph, err := parsePhoneme(translation)
+		if err != nil {
+			return "", &PronunciationError{Word: word.Word, Message: err.Error()}
+		}
+		return ph, nil
+	}
+	pronunciation, err := c.GetPronunciation(translation, voice, "ipa")
+	if err != nil {
+		return "", &PronunciationError{Word: word.Word, Message: err.Error()}
+	}
+	if len(pronunciation) == 0 {
+		return "", &PronunciationError{Word: word.Word, Message: "no pronunciation for " + translation}
+	}
+	return pronunciation, nil
+}
+
+// AddValidatedWords is like AddWords, but checks the translation of every word with ValidateWord() first;
+// no word is added if any translation is invalid.
+func (c Client) AddValidatedWords(customization_id string, voice string, words []Word) error {
+	for _, w := range words {
+		if _, err := c.ValidateWord(voice, w); err != nil {
+			return err
+		}
+	}
+	return c.AddWords(customization_id, words)
+}
+
+// parsePhoneme returns the alphabet ('ipa' or 'ibm') and pronunciation of a phoneme translation
+func parsePhoneme(translation string) (string, string, error) {
+	var phoneme struct {
+		XMLName  xml.Name
+		Alphabet string `xml:"alphabet,attr"`
+		Ph       string `xml:"ph,attr"`
+	}
+	if err := xml.Unmarshal([]byte(translation), &phoneme); err != nil {
+		return "", "", err
+	}
+	alphabet := strings.ToLower(phoneme.Alphabet)
+	switch {
+	case phoneme.XMLName.Local != "phoneme":
+		return "", "", errors.New("unsupported element " + phoneme.XMLName.Local)
+	case alphabet != "ipa" && alphabet != "ibm":
+		return "", "", errors.New("unsupported alphabet " + phoneme.Alphabet)
+	case len(phoneme.Ph) == 0:
+		return "", "", errors.New("empty phoneme")
+	}
+	return alphabet, phoneme.Ph, nil
+}
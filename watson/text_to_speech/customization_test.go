@@ -0,0 +1,97 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+// newFakeClient returns a client of a local server calling handler
+func newFakeClient(t *testing.T, handler http.HandlerFunc) (Client, func()) {
+	ts := httptest.NewServer(handler)
+	c, err := NewClient(watson.Config{Credentials: watson.Credentials{Url: ts.URL, Username: "uuuu", Password: "pppp"}})
+	if err != nil {
+		t.Fatalf("NewClient() failed %#v\n", err)
+	}
+	return c, ts.Close
+}
+
+func TestPhonemeTranslation(t *testing.T) {
+	if tr := PhonemeTranslation("SPR", `.1Ek.0sIk`); tr != `<phoneme alphabet="ibm" ph=".1Ek.0sIk"></phoneme>` {
+		t.Errorf("PhonemeTranslation() returned %#v\n", tr)
+	}
+}
+
+func TestAddValidatedWords(t *testing.T) {
+	var added WordList
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/pronunciation":
+			if r.URL.Query().Get("voice") != "en-US_AllisonVoice" {
+				t.Errorf("unexpected request %s\n", r.URL)
+			}
+			if r.URL.Query().Get("text") == "zzxq" {
+				w.Write([]byte(`{"pronunciation": ""}`))
+				return
+			}
+			w.Write([]byte(`{"pronunciation": "ˈaɪ ˈtrɪ.pl̩ ˈi"}`))
+		case r.Method == "POST" && r.URL.Path == "/v1/customizations/cust-1/words":
+			json.NewDecoder(r.Body).Decode(&added)
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s\n", r.Method, r.URL)
+		}
+	})
+	defer closeServer()
+
+	words := []Word{
+		{Word: "IEEE", Translation: "I triple E"},
+		{Word: "tomato", Translation: PhonemeTranslation("ipa", "təˈmɑtoʊ")},
+	}
+	pronunciation, err := c.ValidateWord("en-US_AllisonVoice", words[0])
+	if err != nil || pronunciation != "ˈaɪ ˈtrɪ.pl̩ ˈi" {
+		t.Errorf("ValidateWord() returned %#v, %#v\n", pronunciation, err)
+	}
+	if err = c.AddValidatedWords("cust-1", "en-US_AllisonVoice", words); err != nil {
+		t.Errorf("AddValidatedWords() failed %#v\n", err)
+	}
+	if !reflect.DeepEqual(added.Words, words) {
+		t.Errorf("AddValidatedWords() sent %#v\n", added)
+	}
+
+	added = WordList{}
+	invalid := [][]Word{
+		{{Word: "x", Translation: "zzxq"}},
+		{{Word: "x", Translation: `<phoneme alphabet="sampa" ph="t@"></phoneme>`}},
+		{{Word: "x", Translation: `<phoneme alphabet="ipa" ph="t@">`}},
+		{{Word: "x", Translation: " "}},
+	}
+	for _, w := range invalid {
+		err = c.AddValidatedWords("cust-1", "en-US_AllisonVoice", w)
+		if _, ok := err.(*PronunciationError); !ok {
+			t.Errorf("AddValidatedWords(%#v) returned %#v\n", w, err)
+		}
+	}
+	if added.Words != nil {
+		t.Errorf("AddValidatedWords() added invalid words %#v\n", added)
+	}
+}
@@ -0,0 +1,263 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// concatFLAC joins the frames of FLAC files, keeping the metadata of the first one (except its seek table,
+// which does not apply to the joined stream). Frames are renumbered by sample, as a variable block size
+// stream (the last frame of each file may be shorter than the others), and the stream information is updated
+// with the total number of samples and the block and frame sizes; its MD5 signature is cleared.
+func concatFLAC(parts [][]byte) ([]byte, error) {
+	var info []byte
+	var metadata [][]byte
+	var frames bytes.Buffer
+	var samples uint64
+	minBlock, maxBlock, minFrame, maxFrame := 0, 0, 0, 0
+	for i, b := range parts {
+		if len(b) < 4 || string(b[0:4]) != "fLaC" {
+			return nil, errors.New("invalid FLAC file")
+		}
+		b = b[4:]
+		var streaminfo []byte
+		for last := false; !last; {
+			if len(b) < 4 {
+				return nil, errors.New("truncated FLAC metadata")
+			}
+			last = b[0]&0x80 != 0
+			kind, size := b[0]&0x7f, int(b[1])<<16|int(b[2])<<8|int(b[3])
+			if len(b) < 4+size {
+				return nil, errors.New("truncated FLAC metadata")
+			}
+			switch {
+			case kind == 0 && size == 34:
+				streaminfo = b[4 : 4+size]
+			case kind == 3: // seek table
+			case i == 0:
+				metadata = append(metadata, b[:4+size])
+			}
+			b = b[4+size:]
+		}
+		if streaminfo == nil {
+			return nil, errors.New("FLAC file without stream information")
+		}
+		// sampling rate, channels and bits per sample
+		if info == nil {
+			info = append([]byte(nil), streaminfo...)
+		} else if !bytes.Equal(info[10:13], streaminfo[10:13]) || info[13]&0xf0 != streaminfo[13]&0xf0 {
+			return nil, errors.New("FLAC files have different formats")
+		}
+
+		for len(b) > 0 {
+			h, ok := parseFLACFrameHeader(b)
+			if !ok {
+				return nil, errors.New("invalid FLAC frame")
+			}
+			end := len(b)
+			for p := h.length; p+1 < len(b); p++ {
+				if b[p] == 0xff && b[p+1]&0xfe == 0xf8 && flacCRC16(b[:p]) == 0 {
+					if _, ok := parseFLACFrameHeader(b[p:]); ok {
+						end = p
+						break
+					}
+				}
+			}
+			frame := h.renumber(b[:end], samples)
+			frames.Write(frame)
+			samples += uint64(h.blockSize)
+			if len(b) > end || i+1 < len(parts) {
+				if minBlock == 0 || h.blockSize < minBlock {
+					minBlock = h.blockSize
+				}
+			}
+			if h.blockSize > maxBlock {
+				maxBlock = h.blockSize
+			}
+			if minFrame == 0 || len(frame) < minFrame {
+				minFrame = len(frame)
+			}
+			if len(frame) > maxFrame {
+				maxFrame = len(frame)
+			}
+			b = b[end:]
+		}
+	}
+	if minBlock == 0 {
+		minBlock = maxBlock
+	}
+
+	binary.BigEndian.PutUint16(info[0:2], uint16(minBlock))
+	binary.BigEndian.PutUint16(info[2:4], uint16(maxBlock))
+	put24(info[4:7], minFrame)
+	put24(info[7:10], maxFrame)
+	info[13] = info[13]&0xf0 | byte(samples>>32)&0x0f
+	binary.BigEndian.PutUint32(info[14:18], uint32(samples))
+	for i := 18; i < 34; i++ {
+		info[i] = 0
+	}
+
+	var out bytes.Buffer
+	out.WriteString("fLaC")
+	header := []byte{0, 0, 0, 34}
+	if len(metadata) == 0 {
+		header[0] |= 0x80
+	}
+	out.Write(header)
+	out.Write(info)
+	for i, m := range metadata {
+		kind := m[0] & 0x7f
+		if i == len(metadata)-1 {
+			kind |= 0x80
+		}
+		out.WriteByte(kind)
+		out.Write(m[1:])
+	}
+	out.Write(frames.Bytes())
+	return out.Bytes(), nil
+}
+
+func put24(b []byte, n int) {
+	b[0], b[1], b[2] = byte(n>>16), byte(n>>8), byte(n)
+}
+
+type flacFrameHeader struct {
+	// length of the header, including its checksum
+	length    int
+	blockSize int
+	// offset and length of the coded frame or sample number
+	numberOffset, numberLength int
+}
+
+// parseFLACFrameHeader parses the header of the FLAC frame at the start of b
+func parseFLACFrameHeader(b []byte) (flacFrameHeader, bool) {
+	if len(b) < 6 || b[0] != 0xff || b[1]&0xfe != 0xf8 || b[3]&0x01 != 0 {
+		return flacFrameHeader{}, false
+	}
+	bsCode, srCode := b[2]>>4, b[2]&0x0f
+	if bsCode == 0 || srCode == 0x0f || b[3]>>4 > 10 || (b[3]>>1)&0x07 == 3 {
+		return flacFrameHeader{}, false
+	}
+	h := flacFrameHeader{numberOffset: 4, numberLength: 1}
+	for c := b[4]; c&0x80 != 0; c <<= 1 {
+		h.numberLength++
+	}
+	switch {
+	case h.numberLength == 2:
+		return flacFrameHeader{}, false
+	case h.numberLength > 2:
+		h.numberLength--
+	}
+	if h.numberLength > 7 {
+		return flacFrameHeader{}, false
+	}
+	p := 4 + h.numberLength
+	switch {
+	case bsCode == 1:
+		h.blockSize = 192
+	case bsCode <= 5:
+		h.blockSize = 576 << (bsCode - 2)
+	case bsCode == 6:
+		if len(b) < p+1 {
+			return flacFrameHeader{}, false
+		}
+		h.blockSize = int(b[p]) + 1
+		p++
+	case bsCode == 7:
+		if len(b) < p+2 {
+			return flacFrameHeader{}, false
+		}
+		h.blockSize = int(binary.BigEndian.Uint16(b[p:])) + 1
+		p += 2
+	default:
+		h.blockSize = 256 << (bsCode - 8)
+	}
+	switch srCode {
+	case 12:
+		p++
+	case 13, 14:
+		p += 2
+	}
+	if len(b) < p+1 || flacCRC8(b[:p]) != b[p] {
+		return flacFrameHeader{}, false
+	}
+	h.length = p + 1
+	return h, true
+}
+
+// renumber returns frame as a frame of a variable block size stream, starting at sample
+func (h flacFrameHeader) renumber(frame []byte, sample uint64) []byte {
+	var out []byte
+	out = append(out, frame[0], frame[1]|0x01, frame[2], frame[3])
+	out = append(out, flacNumber(sample)...)
+	out = append(out, frame[h.numberOffset+h.numberLength:h.length-1]...)
+	out = append(out, flacCRC8(out))
+	out = append(out, frame[h.length:len(frame)-2]...)
+	crc := flacCRC16(out)
+	return append(out, byte(crc>>8), byte(crc))
+}
+
+// flacNumber encodes n with the UTF-8 like coding of FLAC frame headers
+func flacNumber(n uint64) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	// number of continuation bytes, each carrying 6 bits
+	c := 1
+	for n>>uint(6*c) >= 1<<uint(6-c) {
+		c++
+	}
+	b := make([]byte, c+1)
+	for i := c; i > 0; i-- {
+		b[i] = 0x80 | byte(n&0x3f)
+		n >>= 6
+	}
+	b[0] = byte(0xff<<uint(7-c)) | byte(n)
+	return b
+}
+
+func flacCRC8(b []byte) byte {
+	var crc byte
+	for _, c := range b {
+		crc ^= c
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func flacCRC16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
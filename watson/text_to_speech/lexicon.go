@@ -0,0 +1,204 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+type plsLexicon struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/01/pronunciation-lexicon lexicon"`
+	Version  string      `xml:"version,attr"`
+	Alphabet string      `xml:"alphabet,attr"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Lexemes  []plsLexeme `xml:"lexeme"`
+}
+
+type plsLexeme struct {
+	Graphemes []string     `xml:"grapheme"`
+	Phonemes  []plsPhoneme `xml:"phoneme,omitempty"`
+	Aliases   []string     `xml:"alias,omitempty"`
+}
+
+type plsPhoneme struct {
+	Alphabet string `xml:"alphabet,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+// ReadLexicon reads a W3C PLS (Pronunciation Lexicon Specification) document, returning a custom word for
+// each grapheme of its lexemes. The first phoneme or alias of a lexeme (the preferred pronunciation) is used
+// as translation: phonemes become phoneme translations, and aliases translations spelled as they sound.
+// Phonemes must use the 'ipa' alphabet, or the service's SPR alphabet (as 'x-ibm', 'ibm' or 'spr').
+func ReadLexicon(r io.Reader) ([]Word, error) {
+	var lexicon plsLexicon
+	if err := xml.NewDecoder(r).Decode(&lexicon); err != nil {
+		return nil, err
+	}
+	var words []Word
+	for _, lexeme := range lexicon.Lexemes {
+		if len(lexeme.Graphemes) == 0 {
+			return nil, errors.New("lexeme without grapheme")
+		}
+		var translation string
+		switch {
+		case len(lexeme.Phonemes) > 0:
+			alphabet := lexeme.Phonemes[0].Alphabet
+			if len(alphabet) == 0 {
+				alphabet = lexicon.Alphabet
+			}
+			switch strings.ToLower(alphabet) {
+			case "ipa":
+				alphabet = "ipa"
+			case "x-ibm", "ibm", "spr", "x-spr":
+				alphabet = "ibm"
+			default:
+				return nil, errors.New("unsupported alphabet " + alphabet + " for " + lexeme.Graphemes[0])
+			}
+			translation = PhonemeTranslation(alphabet, strings.TrimSpace(lexeme.Phonemes[0].Text))
+		case len(lexeme.Aliases) > 0:
+			translation = strings.TrimSpace(lexeme.Aliases[0])
+		default:
+			return nil, errors.New("lexeme without phoneme or alias for " + lexeme.Graphemes[0])
+		}
+		for _, g := range lexeme.Graphemes {
+			words = append(words, Word{Word: strings.TrimSpace(g), Translation: translation})
+		}
+	}
+	return words, nil
+}
+
+// WriteLexicon writes words as a W3C PLS document for language (for example, en-US). Phoneme translations
+// become phonemes (SPR ones with the 'x-ibm' alphabet), and other translations aliases.
+func WriteLexicon(w io.Writer, language string, words []Word) error {
+	lexicon := plsLexicon{Version: "1.0", Alphabet: "ipa", Lang: language}
+	for _, word := range words {
+		lexeme := plsLexeme{Graphemes: []string{word.Word}}
+		if alphabet, ph, err := parsePhoneme(word.Translation); err == nil {
+			p := plsPhoneme{Text: ph}
+			if alphabet == "ibm" {
+				p.Alphabet = "x-ibm"
+			}
+			lexeme.Phonemes = []plsPhoneme{p}
+		} else if strings.HasPrefix(strings.TrimSpace(word.Translation), "<") {
+			return errors.New("invalid translation of " + word.Word + ": " + err.Error())
+		} else {
+			lexeme.Aliases = []string{strings.TrimSpace(word.Translation)}
+		}
+		lexicon.Lexemes = append(lexicon.Lexemes, lexeme)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(lexicon); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// LexiconDiff contains the changes needed to make the words of a custom voice model match a lexicon
+type LexiconDiff struct {
+	// Words of the lexicon missing from the custom model
+	Add []Word
+	// Words of the lexicon whose translation differs in the custom model
+	Update []Word
+	// Words of the custom model missing from the lexicon
+	Delete []string
+}
+
+// Empty reports whether the diff contains no changes
+func (d LexiconDiff) Empty() bool {
+	return len(d.Add) == 0 && len(d.Update) == 0 && len(d.Delete) == 0
+}
+
+// DiffWords returns the changes needed to turn the current words of a custom voice model into the desired ones.
+// Translations are compared ignoring surrounding space and, for phoneme translations, the markup.
+// If desired contains a word more than once, the last translation is used.
+func DiffWords(current []Word, desired []Word) LexiconDiff {
+	have := make(map[string]string, len(current))
+	for _, w := range current {
+		have[w.Word] = canonicalTranslation(w.Translation)
+	}
+	want := make(map[string]Word, len(desired))
+	var order []string
+	for _, w := range desired {
+		if _, ok := want[w.Word]; !ok {
+			order = append(order, w.Word)
+		}
+		want[w.Word] = w
+	}
+	var diff LexiconDiff
+	for _, word := range order {
+		w := want[word]
+		translation, ok := have[word]
+		switch {
+		case !ok:
+			diff.Add = append(diff.Add, w)
+		case translation != canonicalTranslation(w.Translation):
+			diff.Update = append(diff.Update, w)
+		}
+	}
+	for _, w := range current {
+		if _, ok := want[w.Word]; !ok {
+			diff.Delete = append(diff.Delete, w.Word)
+		}
+	}
+	sort.Strings(diff.Delete)
+	return diff
+}
+
+func canonicalTranslation(translation string) string {
+	if alphabet, ph, err := parsePhoneme(translation); err == nil {
+		return PhonemeTranslation(alphabet, ph)
+	}
+	return strings.TrimSpace(translation)
+}
+
+// SyncLexicon makes the words of a custom voice model match the W3C PLS document read from lexicon: words
+// missing from the model are added, words with a different translation are updated, and words missing from
+// the lexicon are deleted. It returns the changes made; if dry_run is true, the changes are only computed.
+// Changes are not atomic: if an error is returned, some of them may have been applied.
+func (c Client) SyncLexicon(customization_id string, lexicon io.Reader, dry_run bool) (LexiconDiff, error) {
+	desired, err := ReadLexicon(lexicon)
+	if err != nil {
+		return LexiconDiff{}, err
+	}
+	current, err := c.ListWords(customization_id)
+	if err != nil {
+		return LexiconDiff{}, err
+	}
+	diff := DiffWords(current.Words, desired)
+	if dry_run {
+		return diff, nil
+	}
+	if changed := append(append([]Word(nil), diff.Add...), diff.Update...); len(changed) > 0 {
+		if err = c.AddWords(customization_id, changed); err != nil {
+			return diff, err
+		}
+	}
+	for _, word := range diff.Delete {
+		if err = c.DeleteWord(customization_id, word); err != nil {
+			return diff, err
+		}
+	}
+	return diff, nil
+}
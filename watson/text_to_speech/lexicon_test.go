@@ -0,0 +1,113 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLexicon = `<?xml version="1.0" encoding="UTF-8"?>
+<lexicon version="1.0" xmlns="http://www.w3.org/2005/01/pronunciation-lexicon" alphabet="ipa" xml:lang="en-US">
+  <lexeme>
+    <grapheme>tomato</grapheme>
+    <phoneme>təˈmɑtoʊ</phoneme>
+    <phoneme>təˈmeɪtoʊ</phoneme>
+  </lexeme>
+  <lexeme>
+    <grapheme>IEEE</grapheme>
+    <grapheme>I.E.E.E.</grapheme>
+    <alias>I triple E</alias>
+  </lexeme>
+  <lexeme>
+    <grapheme>Xbox</grapheme>
+    <phoneme alphabet="x-ibm">.1Eks.0bAks</phoneme>
+  </lexeme>
+</lexicon>`
+
+var testLexiconWords = []Word{
+	{Word: "tomato", Translation: `<phoneme alphabet="ipa" ph="təˈmɑtoʊ"></phoneme>`},
+	{Word: "IEEE", Translation: "I triple E"},
+	{Word: "I.E.E.E.", Translation: "I triple E"},
+	{Word: "Xbox", Translation: `<phoneme alphabet="ibm" ph=".1Eks.0bAks"></phoneme>`},
+}
+
+func TestReadLexicon(t *testing.T) {
+	words, err := ReadLexicon(strings.NewReader(testLexicon))
+	if err != nil || !reflect.DeepEqual(words, testLexiconWords) {
+		t.Errorf("ReadLexicon() returned %#v, %#v\n", words, err)
+	}
+	_, err = ReadLexicon(strings.NewReader(strings.Replace(testLexicon, `alphabet="ipa"`, `alphabet="x-sampa"`, 1)))
+	if err == nil {
+		t.Errorf("ReadLexicon() accepted an unsupported alphabet\n")
+	}
+}
+
+func TestWriteLexicon(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLexicon(&buf, "en-US", testLexiconWords); err != nil {
+		t.Errorf("WriteLexicon() failed %#v\n", err)
+		return
+	}
+	words, err := ReadLexicon(&buf)
+	if err != nil || !reflect.DeepEqual(words, testLexiconWords) {
+		t.Errorf("ReadLexicon() of WriteLexicon() output returned %#v, %#v\n", words, err)
+	}
+}
+
+func TestSyncLexicon(t *testing.T) {
+	var added WordList
+	var deleted []string
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/customizations/cust-1/words":
+			w.Write([]byte(`{"words": [
+				{"word": "tomato", "translation": "<phoneme alphabet=\"IPA\" ph=\"təˈmɑtoʊ\"></phoneme>"},
+				{"word": "IEEE", "translation": "eye triple E"},
+				{"word": "GIF", "translation": "jif"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/v1/customizations/cust-1/words":
+			json.NewDecoder(r.Body).Decode(&added)
+			w.Write([]byte("{}"))
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/v1/customizations/cust-1/words/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1/customizations/cust-1/words/"))
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s\n", r.Method, r.URL)
+		}
+	})
+	defer closeServer()
+
+	expected := LexiconDiff{
+		Add:    []Word{testLexiconWords[2], testLexiconWords[3]},
+		Update: []Word{testLexiconWords[1]},
+		Delete: []string{"GIF"},
+	}
+	diff, err := c.SyncLexicon("cust-1", strings.NewReader(testLexicon), true)
+	if err != nil || !reflect.DeepEqual(diff, expected) || added.Words != nil || deleted != nil {
+		t.Errorf("SyncLexicon() dry run returned %#v, %#v\n", diff, err)
+	}
+	diff, err = c.SyncLexicon("cust-1", strings.NewReader(testLexicon), false)
+	if err != nil || !reflect.DeepEqual(diff, expected) {
+		t.Errorf("SyncLexicon() returned %#v, %#v\n", diff, err)
+	}
+	if !reflect.DeepEqual(added.Words, append(expected.Add, expected.Update...)) || !reflect.DeepEqual(deleted, expected.Delete) {
+		t.Errorf("SyncLexicon() added %#v and deleted %#v\n", added, deleted)
+	}
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+)
+
+// LongFormOptions configures SynthesizeLong()
+type LongFormOptions struct {
+	Voice           string
+	Accept          string
+	CustomizationId string
+	// Maximum size of the text of each call to the service; DefaultMaxChunkSize if zero
+	MaxChunkSize int
+	// Maximum number of concurrent calls to the service; 4 if zero
+	Concurrency int
+}
+
+// SynthesizeLong synthesizes text (plain or SSML) of any length. The text is split into chunks with SplitText(),
+// which are synthesized concurrently, and the resulting audio files are joined in order with ConcatAudio().
+// The first error stops the synthesis of the remaining chunks and is returned.
+func (c Client) SynthesizeLong(ctx context.Context, text string, opts LongFormOptions) ([]byte, error) {
+	chunks, err := SplitText(text, opts.MaxChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := make([][]byte, len(chunks))
+	var mu sync.Mutex
+	var firstErr error
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			audio, err := c.SynthesizeReader(ctx, chunk, opts.Voice, opts.Accept, opts.CustomizationId)
+			if err == nil {
+				parts[i], err = ioutil.ReadAll(audio)
+				audio.Close()
+			}
+			if err != nil {
+				// later errors are usually caused by the cancellation
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ConcatAudio(opts.Accept, parts)
+}
@@ -0,0 +1,255 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitText(t *testing.T) {
+	text := "First sentence. Second one! Is this the third? \"Quoted.\" Then a very long sentence without much punctuation at all\n\nNew paragraph"
+	chunks, err := SplitText(text, 40)
+	expected := []string{"First sentence. Second one! ", "Is this the third? \"Quoted.\" ", "Then a very long sentence without much ", "punctuation at all\n\nNew paragraph"}
+	if err != nil || !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("SplitText() returned %#v, %#v\n", chunks, err)
+	}
+
+	// the spaces between words split across chunks do not make chunks of their own
+	chunks, err = SplitText("héllo wörld ünïcödé", 3)
+	for _, chunk := range chunks {
+		if len(strings.TrimSpace(chunk)) == 0 {
+			t.Errorf("SplitText() returned chunks without text %#v\n", chunks)
+			break
+		}
+	}
+	if err != nil || strings.Replace(strings.Join(chunks, ""), " ", "", -1) != "héllowörldünïcödé" {
+		t.Errorf("SplitText() of long non-ASCII words returned %#v, %#v\n", chunks, err)
+	}
+
+	chunks, err = SplitText("Supercalifragilisticexpialidocious", 10)
+	if err != nil || strings.Join(chunks, "") != "Supercalifragilisticexpialidocious" || len(chunks) != 4 {
+		t.Errorf("SplitText() of a long word returned %#v, %#v\n", chunks, err)
+	}
+}
+
+func TestSplitTextSSML(t *testing.T) {
+	text := `<?xml version="1.0"?><speak version="1.0"><p>Fish &amp; chips are ready. <prosody rate="slow">Please come to the counter. Bring your ticket.</prosody></p><p>Thank you<break time="1s"/> and enjoy.</p></speak>`
+	chunks, err := SplitText(text, 100)
+	if err != nil {
+		t.Errorf("SplitText() failed %#v\n", err)
+		return
+	}
+	expected := []string{
+		`<speak version="1.0"><p>Fish &amp; chips are ready. </p></speak>`,
+		`<speak version="1.0"><p><prosody rate="slow">Please come to the counter. </prosody></p></speak>`,
+		`<speak version="1.0"><p><prosody rate="slow">Bring your ticket.</prosody></p></speak>`,
+		`<speak version="1.0"><p>Thank you<break time="1s"/> and enjoy.</p></speak>`,
+	}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("SplitText() returned %#v\n", chunks)
+	}
+	for _, c := range chunks {
+		d := xml.NewDecoder(strings.NewReader(c))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil || len(c) > 100 {
+				t.Errorf("SplitText() returned invalid chunk %#v (%v)\n", c, err)
+				break
+			}
+		}
+	}
+	if _, err = SplitText(`<speak><prosody rate="slow" pitch="high" volume="loud">Hi</prosody></speak>`, 40); err == nil {
+		t.Errorf("SplitText() split a tag\n")
+	}
+}
+
+func testWAV(data string, streamed bool) []byte {
+	var b bytes.Buffer
+	riff, size := uint32(36+len(data)+len(data)%2), uint32(len(data))
+	if streamed {
+		riff, size = 0xffffffff, 0xffffffff
+	}
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, riff)
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, []uint16{1, 1})
+	binary.Write(&b, binary.LittleEndian, []uint32{22050, 44100})
+	binary.Write(&b, binary.LittleEndian, []uint16{2, 16})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, size)
+	b.WriteString(data)
+	if !streamed && len(data)%2 == 1 {
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+func TestConcatWAV(t *testing.T) {
+	audio, err := ConcatAudio("audio/wav", [][]byte{testWAV("aabb", true), testWAV("ccd", false)})
+	if err != nil || !bytes.Equal(audio, testWAV("aabbccd", false)) {
+		t.Errorf("ConcatAudio() returned %#v, %#v\n", audio, err)
+	}
+}
+
+// testFLAC returns a FLAC file with frames of a fixed block size of 1152 samples, except for the last one
+func testFLAC(frames int, last int) []byte {
+	b := []byte("fLaC")
+	info := make([]byte, 34)
+	info[10], info[11], info[12], info[13] = 0x05, 0x62, 0x20, 0xf0 // 22050Hz, 1 channel, 16 bits
+	b = append(b, 0x80, 0, 0, 34)
+	b = append(b, info...)
+	for i := 0; i < frames; i++ {
+		frame := []byte{0xff, 0xf8, 0x39, 0x08}
+		if i == frames-1 {
+			frame[2] = 0x79 // block size in 16 bits
+		}
+		frame = append(frame, flacNumber(uint64(i))...)
+		if i == frames-1 {
+			frame = append(frame, byte((last-1)>>8), byte(last-1))
+		}
+		frame = append(frame, flacCRC8(frame))
+		frame = append(frame, bytes.Repeat([]byte{byte(i), 0xff, 0xf8}, 20)...)
+		crc := flacCRC16(frame)
+		b = append(b, append(frame, byte(crc>>8), byte(crc))...)
+	}
+	return b
+}
+
+func TestConcatFLAC(t *testing.T) {
+	audio, err := ConcatAudio("audio/flac", [][]byte{testFLAC(3, 100), testFLAC(200, 500)})
+	if err != nil {
+		t.Errorf("ConcatAudio() failed %#v\n", err)
+		return
+	}
+	info := audio[8:42]
+	samples := uint64(info[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if samples != 2*1152+100+199*1152+500 || binary.BigEndian.Uint16(info[0:2]) != 100 || binary.BigEndian.Uint16(info[2:4]) != 1152 {
+		t.Errorf("ConcatAudio() returned stream information %#v\n", info)
+	}
+	var sample uint64
+	frames := 0
+	for b := audio[42:]; len(b) > 0; frames++ {
+		h, ok := parseFLACFrameHeader(b)
+		if !ok || b[1] != 0xf9 || !bytes.Equal(b[4:4+h.numberLength], flacNumber(sample)) {
+			t.Errorf("ConcatAudio() returned invalid frame %d %#v\n", frames, b[:8])
+			return
+		}
+		// frames of the test files have 60 bytes of data
+		n := h.length + 60 + 2
+		if flacCRC16(b[:n]) != 0 {
+			t.Errorf("ConcatAudio() returned frame %d with an invalid checksum\n", frames)
+		}
+		sample += uint64(h.blockSize)
+		b = b[n:]
+	}
+	if frames != 203 || sample != samples {
+		t.Errorf("ConcatAudio() returned %d frames of %d samples\n", frames, sample)
+	}
+}
+
+// testOpus returns an Ogg Opus file with header pages and audio pages of 960 samples each
+func testOpus(serial uint32, pages int) []byte {
+	var b bytes.Buffer
+	head := append([]byte("OpusHead"), 1, 1, 0x38, 0x01, 0x22, 0x56, 0, 0, 0, 0, 0)
+	oggPage{headerType: 0x02, serial: serial, segments: []byte{byte(len(head))}, data: head}.write(&b)
+	oggPage{serial: serial, seq: 1, segments: []byte{12}, data: []byte("OpusTags....")}.write(&b)
+	for i := 0; i < pages; i++ {
+		p := oggPage{granule: int64(312 + 960*(i+1)), serial: serial, seq: uint32(2 + i), segments: []byte{3}, data: []byte{byte(i), 1, 2}}
+		if i == pages-1 {
+			p.headerType = 0x04
+		}
+		p.write(&b)
+	}
+	return b.Bytes()
+}
+
+func TestConcatOgg(t *testing.T) {
+	audio, err := ConcatAudio("audio/ogg;codecs=opus", [][]byte{testOpus(7, 2), testOpus(9, 3)})
+	if err != nil {
+		t.Errorf("ConcatAudio() failed %#v\n", err)
+		return
+	}
+	pages, err := oggPages(audio)
+	if err != nil || len(pages) != 7 {
+		t.Errorf("ConcatAudio() returned %d pages, %#v\n", len(pages), err)
+		return
+	}
+	granules := []int64{0, 0, 1272, 2232, 3504, 4464, 5424}
+	for i, p := range pages {
+		flags := byte(0)
+		switch i {
+		case 0:
+			flags = 0x02
+		case len(pages) - 1:
+			flags = 0x04
+		}
+		if p.serial != 7 || p.seq != uint32(i) || p.granule != granules[i] || p.headerType != flags {
+			t.Errorf("ConcatAudio() returned page %d %#v\n", i, p)
+		}
+	}
+	// checksums are valid
+	var rewritten bytes.Buffer
+	for _, p := range pages {
+		p.write(&rewritten)
+	}
+	if !bytes.Equal(rewritten.Bytes(), audio) {
+		t.Errorf("ConcatAudio() returned pages with invalid checksums\n")
+	}
+
+	// files of another sample rate are refused, while the pre-skip may differ
+	other := testOpus(9, 3)
+	other[28+10] = 0x40
+	if _, err := ConcatAudio("audio/ogg;codecs=opus", [][]byte{testOpus(7, 2), other}); err != nil {
+		t.Errorf("ConcatAudio() of a different pre-skip failed %#v\n", err)
+	}
+	other = testOpus(9, 3)
+	other[28+12] = 0x80
+	if _, err := ConcatAudio("audio/ogg;codecs=opus", [][]byte{testOpus(7, 2), other}); err == nil {
+		t.Errorf("ConcatAudio() of different sample rates succeeded\n")
+	}
+}
+
+func TestSynthesizeLong(t *testing.T) {
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		// answer the first chunks last
+		time.Sleep(time.Duration(100-len(body.Text)) * time.Millisecond)
+		w.Write(testWAV(strings.TrimSpace(body.Text), true))
+	})
+	defer closeServer()
+
+	audio, err := c.SynthesizeLong(context.Background(), "One two three. Four five. Six.", LongFormOptions{Accept: "audio/wav", MaxChunkSize: 12})
+	if err != nil || !bytes.Equal(audio, testWAV("One twothree.Four five.Six.", false)) {
+		t.Errorf("SynthesizeLong() returned %q, %#v\n", audio, err)
+	}
+}
@@ -0,0 +1,314 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxChunkSize is the size, in bytes, of the largest text accepted by Synthesize()
+const DefaultMaxChunkSize = 5000
+
+// atom is a piece of text which SplitText() does not split further
+type atom struct {
+	text string
+	// open tag of a start element, with the name of the element; empty for text and other markup
+	open, name string
+	// set for an end tag
+	close bool
+	// a chunk may end after the atom without splitting a sentence
+	boundary bool
+}
+
+// SplitText splits text into chunks of at most max bytes (DefaultMaxChunkSize if zero), for synthesis by separate
+// calls to Synthesize(). Chunks end at sentence boundaries where possible, and otherwise between words.
+// If text is SSML (that is, if it starts with '<'), every chunk is valid SSML: elements spanning chunks are
+// closed at the end of a chunk and opened again, with the same attributes, at the start of the next one.
+// Chunk boundaries never split SSML tags, so an error is returned if a tag (with the tags enclosing it)
+// does not fit in max bytes.
+func SplitText(text string, max int) ([]string, error) {
+	if max <= 0 {
+		max = DefaultMaxChunkSize
+	}
+	ssml := strings.HasPrefix(strings.TrimSpace(text), "<")
+	var atoms []atom
+	if ssml {
+		var err error
+		atoms, err = markupAtoms(text)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		atoms = textAtoms(text)
+	}
+
+	var chunks []string
+	var stack []atom
+	for start := 0; start < len(atoms); {
+		// find the longest run of atoms fitting in max bytes, once enclosed in the tags left open by the
+		// previous chunks, preferably ending at a boundary
+		size, suffix := 0, 0
+		for _, a := range stack {
+			size += len(a.open)
+			suffix += len(a.closeTag())
+		}
+		open := append([]atom(nil), stack...)
+		end, boundary := start, -1
+		for ; end < len(atoms); end++ {
+			a := atoms[end]
+			s := suffix
+			if a.close && len(open) > 0 {
+				s -= len(open[len(open)-1].closeTag())
+			} else if len(a.open) > 0 {
+				s += len(a.closeTag())
+			}
+			if size+len(a.text)+s > max {
+				break
+			}
+			size, suffix = size+len(a.text), s
+			if a.close && len(open) > 0 {
+				open = open[:len(open)-1]
+			} else if len(a.open) > 0 {
+				open = append(open, a)
+			}
+			if a.boundary || end+1 == len(atoms) {
+				boundary = end + 1
+			}
+		}
+		if end == start {
+			// split a word which does not fit in a chunk on its own
+			a := atoms[start]
+			n := 0
+			if len(a.open) == 0 && !a.close && !strings.HasPrefix(a.text, "<") {
+				n = splitWord(a.text, max-size-suffix, ssml)
+			}
+			if n == 0 {
+				return nil, errors.New("text does not fit in chunks of the maximum size")
+			}
+			atoms = append(atoms[:start], append([]atom{{text: a.text[:n]}, {text: a.text[n:], boundary: a.boundary}}, atoms[start+1:]...)...)
+			continue
+		}
+		if boundary > 0 {
+			end = boundary
+		}
+
+		var chunk bytes.Buffer
+		for _, a := range stack {
+			chunk.WriteString(a.open)
+		}
+		for _, a := range atoms[start:end] {
+			chunk.WriteString(a.text)
+			if a.close && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			} else if len(a.open) > 0 {
+				stack = append(stack, a)
+			}
+		}
+		// keep end tags following the chunk in it, rather than in a chunk without text; they take the
+		// place of the tags closing the chunk
+		for end < len(atoms) && atoms[end].close && len(stack) > 0 && len(atoms[end].text) <= len(stack[len(stack)-1].closeTag()) {
+			chunk.WriteString(atoms[end].text)
+			stack = stack[:len(stack)-1]
+			end++
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			chunk.WriteString(stack[i].closeTag())
+		}
+		// chunks without text, such as the space following a word split across chunks, are rejected by the service
+		content := chunk.String()
+		if ssml {
+			content = stripTags(content)
+		}
+		if len(strings.TrimSpace(content)) > 0 {
+			chunks = append(chunks, chunk.String())
+		}
+		start = end
+	}
+	return chunks, nil
+}
+
+func (a atom) closeTag() string {
+	return "</" + a.name + ">"
+}
+
+// splitWord returns the length of the longest prefix of word no longer than max bytes, without splitting
+// runes, nor entities if escaped is set
+func splitWord(word string, max int, escaped bool) int {
+	n := 0
+	for n < len(word) {
+		_, l := utf8.DecodeRuneInString(word[n:])
+		if escaped && word[n] == '&' {
+			if e := strings.IndexByte(word[n:], ';'); e > 0 {
+				l = e + 1
+			}
+		}
+		if n+l > max {
+			break
+		}
+		n += l
+	}
+	return n
+}
+
+// textAtoms splits text into the words of its sentences; the last word of a sentence is a boundary
+func textAtoms(text string) []atom {
+	var atoms []atom
+	for len(text) > 0 {
+		n := sentenceEnd(text)
+		atoms = append(atoms, wordAtoms(text[:n])...)
+		atoms[len(atoms)-1].boundary = true
+		text = text[n:]
+	}
+	return atoms
+}
+
+// sentenceEnd returns the length of the first sentence of text, including the space following it. Sentences end
+// with '.', '!' or '?' (possibly followed by closing quotes or brackets) before a space, or with a blank line.
+func sentenceEnd(text string) int {
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		i += n
+		switch {
+		case r == '.' || r == '!' || r == '?':
+			for i < len(text) && strings.ContainsRune(`"')]»”’`, rune(text[i])) {
+				i++
+			}
+			if i < len(text) && !unicode.IsSpace(rune(text[i])) {
+				continue
+			}
+		case r == '\n' && strings.HasPrefix(strings.TrimLeft(text[i:], " \t\r"), "\n"):
+		default:
+			continue
+		}
+		for i < len(text) && unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+		return i
+	}
+	return len(text)
+}
+
+// wordAtoms splits text into words, with the space following them
+func wordAtoms(text string) []atom {
+	var atoms []atom
+	for len(text) > 0 {
+		i := strings.IndexFunc(text, unicode.IsSpace)
+		if i < 0 {
+			i = len(text)
+		}
+		for i < len(text) && unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+		atoms = append(atoms, atom{text: text[:i]})
+		text = text[i:]
+	}
+	return atoms
+}
+
+// markupAtoms splits SSML into tags and sentences. The XML declaration and comments are dropped.
+// End tags of paragraphs and sentences, and breaks, are boundaries.
+func markupAtoms(text string) ([]atom, error) {
+	var atoms []atom
+	for len(text) > 0 {
+		i := strings.IndexByte(text, '<')
+		if i != 0 {
+			if i < 0 {
+				i = len(text)
+			}
+			atoms = append(atoms, textAtoms(text[:i])...)
+			text = text[i:]
+			continue
+		}
+		if strings.HasPrefix(text, "<!--") {
+			e := strings.Index(text, "-->")
+			if e < 0 {
+				return nil, errors.New("unterminated comment in SSML")
+			}
+			text = text[e+3:]
+			continue
+		}
+		e := tagEnd(text)
+		if e < 0 {
+			return nil, errors.New("unterminated tag in SSML")
+		}
+		tag := text[:e]
+		text = text[e:]
+		name := strings.TrimLeft(tag[1:], "/")
+		if n := strings.IndexFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == '/' || r == '>' }); n >= 0 {
+			name = name[:n]
+		}
+		switch {
+		case strings.HasPrefix(tag, "<?") || strings.HasPrefix(tag, "<!"):
+		case strings.HasPrefix(tag, "</"):
+			atoms = append(atoms, atom{text: tag, close: true, boundary: oneOfNames(name, "p", "s", "paragraph", "sentence")})
+		case strings.HasSuffix(tag, "/>"):
+			atoms = append(atoms, atom{text: tag, boundary: name == "break"})
+		default:
+			atoms = append(atoms, atom{text: tag, open: tag, name: name})
+		}
+	}
+	return atoms, nil
+}
+
+// tagEnd returns the length of the tag at the start of text, skipping '>' in quoted attribute values, or -1
+func tagEnd(text string) int {
+	var quote byte
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// stripTags returns text without its SSML tags
+func stripTags(text string) string {
+	var b bytes.Buffer
+	for len(text) > 0 {
+		i := strings.IndexByte(text, '<')
+		if i < 0 {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString(text[:i])
+		e := tagEnd(text[i:])
+		if e < 0 {
+			break
+		}
+		text = text[i+e:]
+	}
+	return b.String()
+}
+
+func oneOfNames(name string, names ...string) bool {
+	for _, n := range names {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
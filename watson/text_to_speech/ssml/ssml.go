@@ -0,0 +1,292 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ssml provides a builder for the SSML markup accepted by the Watson Text to Speech service.
+// Text is escaped and attribute values are validated as they are added, so that the result can be passed
+// as is to text_to_speech.Client.Synthesize.
+//
+//	b := ssml.New()
+//	b.Text("Your code is").SayAs("digits", "", "4213").Break("", 500*time.Millisecond)
+//	b.Prosody(ssml.Prosody{Rate: "slow"}, func(b *ssml.Builder) {
+//		b.Text("please repeat it after the tone.")
+//	})
+//	text, err := b.BuildFor("en-US_AllisonVoice")
+package ssml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Builder accumulates SSML markup. The first invalid element or attribute is recorded and returned by
+// Build; elements added after it are ignored.
+type Builder struct {
+	buf      bytes.Buffer
+	elements map[string]bool
+	err      error
+}
+
+// New creates an empty builder
+func New() *Builder {
+	return &Builder{elements: make(map[string]bool)}
+}
+
+// Text adds text, escaping the characters that are special in SSML
+func (b *Builder) Text(text string) *Builder {
+	if b.err == nil {
+		xml.EscapeText(&b.buf, []byte(text))
+	}
+	return b
+}
+
+// Break adds a pause. strength is one of "none", "x-weak", "weak", "medium", "strong" or "x-strong";
+// if empty, the pause lasts d instead.
+func (b *Builder) Break(strength string, d time.Duration) *Builder {
+	switch {
+	case len(strength) > 0:
+		if !oneOf(strength, "none", "x-weak", "weak", "medium", "strong", "x-strong") {
+			return b.fail("invalid break strength " + strconv.Quote(strength))
+		}
+		return b.empty("break", "strength", strength)
+	case d < 0:
+		return b.fail("invalid break time " + d.String())
+	}
+	return b.empty("break", "time", strconv.FormatInt(int64(d/time.Millisecond), 10)+"ms")
+}
+
+// SayAs adds text to be read as interpret_as, one of "letters", "digits", "cardinal", "number", "ordinal",
+// "date", "vxml:boolean", "vxml:currency", "vxml:date", "vxml:digits", "vxml:number" or "vxml:phone".
+// format is only used by "date", e.g. "mdy".
+func (b *Builder) SayAs(interpret_as string, format string, text string) *Builder {
+	if !oneOf(interpret_as, "letters", "digits", "cardinal", "number", "ordinal", "date",
+		"vxml:boolean", "vxml:currency", "vxml:date", "vxml:digits", "vxml:number", "vxml:phone") {
+		return b.fail("invalid say-as interpret-as " + strconv.Quote(interpret_as))
+	}
+	if len(format) > 0 && interpret_as != "date" {
+		return b.fail("say-as format is only valid with interpret-as date")
+	}
+	if len(format) > 0 && !oneOf(format, "mdy", "dmy", "ymd", "md", "dm", "ym", "my", "d", "m", "y") {
+		return b.fail("invalid say-as format " + strconv.Quote(format))
+	}
+	return b.element("say-as", []string{"interpret-as", interpret_as, "format", format}, func(b *Builder) { b.Text(text) })
+}
+
+// Phoneme adds text pronounced as ph. alphabet is "ipa" or "ibm" (the service's Symbolic Phonetic
+// Representation); if empty, "ipa" is used.
+func (b *Builder) Phoneme(alphabet string, ph string, text string) *Builder {
+	if len(alphabet) == 0 {
+		alphabet = "ipa"
+	}
+	if !oneOf(alphabet, "ipa", "ibm") {
+		return b.fail("invalid phoneme alphabet " + strconv.Quote(alphabet))
+	}
+	if len(ph) == 0 {
+		return b.fail("empty phoneme pronunciation")
+	}
+	return b.element("phoneme", []string{"alphabet", alphabet, "ph", ph}, func(b *Builder) { b.Text(text) })
+}
+
+// Prosody contains the attributes of a prosody element; empty fields are omitted
+type Prosody struct {
+	// "x-low", "low", "medium", "high", "x-high", "default", or a relative ("+10%", "-2st") or absolute ("150Hz") value
+	Pitch string
+	// "x-slow", "slow", "medium", "fast", "x-fast", "default", or a relative ("-20%") or absolute (words per minute) value
+	Rate string
+	// "silent", "x-soft", "soft", "medium", "loud", "x-loud", "default", or a relative ("+6dB", "-10%") value
+	Volume string
+}
+
+var (
+	pitchValue  = regexp.MustCompile(`^([+-]?[0-9]+(\.[0-9]+)?(Hz|st|%)|[0-9]+(\.[0-9]+)?Hz)$`)
+	rateValue   = regexp.MustCompile(`^([+-]?[0-9]+(\.[0-9]+)?%|[0-9]+)$`)
+	volumeValue = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?(dB|%)$`)
+)
+
+// Prosody adds the markup added by f, spoken with the pitch, rate and volume of p
+func (b *Builder) Prosody(p Prosody, f func(*Builder)) *Builder {
+	if len(p.Pitch) > 0 && !oneOf(p.Pitch, "x-low", "low", "medium", "high", "x-high", "default") && !pitchValue.MatchString(p.Pitch) {
+		return b.fail("invalid prosody pitch " + strconv.Quote(p.Pitch))
+	}
+	if len(p.Rate) > 0 && !oneOf(p.Rate, "x-slow", "slow", "medium", "fast", "x-fast", "default") && !rateValue.MatchString(p.Rate) {
+		return b.fail("invalid prosody rate " + strconv.Quote(p.Rate))
+	}
+	if len(p.Volume) > 0 && !oneOf(p.Volume, "silent", "x-soft", "soft", "medium", "loud", "x-loud", "default") && !volumeValue.MatchString(p.Volume) {
+		return b.fail("invalid prosody volume " + strconv.Quote(p.Volume))
+	}
+	return b.element("prosody", []string{"pitch", p.Pitch, "rate", p.Rate, "volume", p.Volume}, f)
+}
+
+// Emphasis adds the markup added by f, spoken with level, one of "strong", "moderate", "none" or "reduced";
+// if empty, "moderate" is used.
+func (b *Builder) Emphasis(level string, f func(*Builder)) *Builder {
+	if len(level) > 0 && !oneOf(level, "strong", "moderate", "none", "reduced") {
+		return b.fail("invalid emphasis level " + strconv.Quote(level))
+	}
+	return b.element("emphasis", []string{"level", level}, f)
+}
+
+// VoiceTransformation contains the attributes of a voice-transformation element; empty fields are omitted
+type VoiceTransformation struct {
+	// "Young", "Soft" or "Custom"
+	Type string
+	// Strength of a Young or Soft transformation, from "0%" to "100%"
+	Strength string
+	// Attributes of a Custom transformation, as percentages from "-100%" to "100%"; Timbre may also
+	// be "Sunrise" or "Breeze".
+	Pitch          string
+	PitchRange     string
+	GlottalTension string
+	Breathiness    string
+	Rate           string
+	Timbre         string
+	TimbreExtent   string
+}
+
+var percentValue = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?%$`)
+
+// VoiceTransformation adds the markup added by f, spoken with the voice transformed by t.
+// Only supported by the US English voices.
+func (b *Builder) VoiceTransformation(t VoiceTransformation, f func(*Builder)) *Builder {
+	switch t.Type {
+	case "Young", "Soft":
+		if len(t.Pitch+t.PitchRange+t.GlottalTension+t.Breathiness+t.Rate+t.Timbre+t.TimbreExtent) > 0 {
+			return b.fail("voice-transformation attributes other than strength require type Custom")
+		}
+	case "Custom":
+		if len(t.Strength) > 0 {
+			return b.fail("voice-transformation strength requires type Young or Soft")
+		}
+	default:
+		return b.fail("invalid voice-transformation type " + strconv.Quote(t.Type))
+	}
+	attrs := []string{"type", t.Type, "strength", t.Strength, "pitch", t.Pitch, "pitch_range", t.PitchRange,
+		"glottal_tension", t.GlottalTension, "breathiness", t.Breathiness, "rate", t.Rate,
+		"timbre", t.Timbre, "timbre_extent", t.TimbreExtent}
+	for i := 2; i < len(attrs); i += 2 {
+		v := attrs[i+1]
+		if len(v) == 0 || (attrs[i] == "timbre" && oneOf(v, "Sunrise", "Breeze")) {
+			continue
+		}
+		if !percentValue.MatchString(v) {
+			return b.fail("invalid voice-transformation " + attrs[i] + " " + strconv.Quote(v))
+		}
+		if n, _ := strconv.ParseFloat(v[:len(v)-1], 64); n < -100 || n > 100 {
+			return b.fail("invalid voice-transformation " + attrs[i] + " " + strconv.Quote(v))
+		}
+	}
+	return b.element("voice-transformation", attrs, f)
+}
+
+// ExpressAs adds the markup added by f, spoken in style, one of "GoodNews", "Apology" or "Uncertainty".
+// Only supported by the en-US_AllisonVoice voice.
+func (b *Builder) ExpressAs(style string, f func(*Builder)) *Builder {
+	if !oneOf(style, "GoodNews", "Apology", "Uncertainty") {
+		return b.fail("invalid express-as type " + strconv.Quote(style))
+	}
+	return b.element("express-as", []string{"type", style}, f)
+}
+
+// Mark adds a marker named name, whose time is reported when synthesizing over the websocket interface
+// (see text_to_speech.Client.SynthesizeStream)
+func (b *Builder) Mark(name string) *Builder {
+	if len(name) == 0 {
+		return b.fail("empty mark name")
+	}
+	return b.empty("mark", "name", name)
+}
+
+// Err returns the first error found while building, if any
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Build returns the markup wrapped in a speak element, or the first error found while building
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return `<speak version="1.0">` + b.buf.String() + `</speak>`, nil
+}
+
+// BuildFor is like Build, but also returns an error if the markup uses elements not supported by voice
+func (b *Builder) BuildFor(voice string) (string, error) {
+	for e := range b.elements {
+		if !Supports(voice, e) {
+			return "", errors.New("voice " + voice + " does not support SSML element " + e)
+		}
+	}
+	return b.Build()
+}
+
+// element adds an element with the non-empty attributes in attrs (pairs of names and values), and the
+// markup added by f as content
+func (b *Builder) element(name string, attrs []string, f func(*Builder)) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.open(name, attrs)
+	if f != nil {
+		f(b)
+	}
+	if b.err == nil {
+		b.buf.WriteString("</" + name + ">")
+	}
+	return b
+}
+
+// empty adds an element with no content
+func (b *Builder) empty(name string, attrs ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.open(name, attrs)
+	b.buf.Truncate(b.buf.Len() - 1)
+	b.buf.WriteString("/>")
+	return b
+}
+
+func (b *Builder) open(name string, attrs []string) {
+	b.elements[name] = true
+	b.buf.WriteString("<" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if len(attrs[i+1]) == 0 {
+			continue
+		}
+		b.buf.WriteString(" " + attrs[i] + `="`)
+		xml.EscapeText(&b.buf, []byte(attrs[i+1]))
+		b.buf.WriteString(`"`)
+	}
+	b.buf.WriteString(">")
+}
+
+func (b *Builder) fail(msg string) *Builder {
+	if b.err == nil {
+		b.err = errors.New(msg)
+	}
+	return b
+}
+
+func oneOf(s string, values ...string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
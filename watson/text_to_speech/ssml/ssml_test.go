@@ -0,0 +1,80 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	b := New()
+	b.Text("Fish & chips <today>").Break("", 300*time.Millisecond).SayAs("digits", "", "4213")
+	b.Prosody(Prosody{Rate: "slow", Pitch: "+10%"}, func(b *Builder) {
+		b.Emphasis("strong", func(b *Builder) { b.Text("now") }).Break("strong", 0)
+	})
+	b.Phoneme("", "təˈmɑtoʊ", "tomato").Mark("end")
+	text, err := b.Build()
+	expected := `<speak version="1.0">Fish &amp; chips &lt;today&gt;<break time="300ms"/><say-as interpret-as="digits">4213</say-as>` +
+		`<prosody pitch="+10%" rate="slow"><emphasis level="strong">now</emphasis><break strength="strong"/></prosody>` +
+		`<phoneme alphabet="ipa" ph="təˈmɑtoʊ">tomato</phoneme><mark name="end"/></speak>`
+	if err != nil || text != expected {
+		t.Errorf("Build() returned %#v, %#v\n", text, err)
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	invalid := []func(b *Builder){
+		func(b *Builder) { b.Break("loud", 0) },
+		func(b *Builder) { b.SayAs("spelling", "", "x") },
+		func(b *Builder) { b.SayAs("digits", "mdy", "12") },
+		func(b *Builder) { b.Phoneme("sampa", "t@", "to") },
+		func(b *Builder) { b.Prosody(Prosody{Rate: "speedy"}, nil) },
+		func(b *Builder) { b.Prosody(Prosody{Volume: "+6"}, nil) },
+		func(b *Builder) { b.VoiceTransformation(VoiceTransformation{Type: "Young", Pitch: "10%"}, nil) },
+		func(b *Builder) { b.VoiceTransformation(VoiceTransformation{Type: "Custom", Breathiness: "150%"}, nil) },
+		func(b *Builder) { b.ExpressAs("Joy", nil) },
+		func(b *Builder) { b.Mark("") },
+		func(b *Builder) { b.Emphasis("strong", func(b *Builder) { b.Break("", -time.Second) }) },
+	}
+	for i, f := range invalid {
+		b := New()
+		f(b)
+		text, err := b.Text("ok").Build()
+		if err == nil {
+			t.Errorf("Build() of invalid markup %d returned %#v\n", i, text)
+		}
+	}
+}
+
+func TestBuildFor(t *testing.T) {
+	b := New().ExpressAs("GoodNews", func(b *Builder) {
+		b.VoiceTransformation(VoiceTransformation{Type: "Custom", Timbre: "Breeze", GlottalTension: "-20%"}, func(b *Builder) {
+			b.Text("You won!")
+		})
+	})
+	text, err := b.BuildFor("en-US_AllisonVoice")
+	expected := `<speak version="1.0"><express-as type="GoodNews"><voice-transformation type="Custom" glottal_tension="-20%" timbre="Breeze">You won!</voice-transformation></express-as></speak>`
+	if err != nil || text != expected {
+		t.Errorf("BuildFor() returned %#v, %#v\n", text, err)
+	}
+	if _, err = b.BuildFor("en-US_MichaelVoice"); err == nil {
+		t.Errorf("BuildFor() accepted express-as for en-US_MichaelVoice\n")
+	}
+	if _, err = b.BuildFor("es-ES_LauraVoice"); err == nil {
+		t.Errorf("BuildFor() accepted voice-transformation for es-ES_LauraVoice\n")
+	}
+}
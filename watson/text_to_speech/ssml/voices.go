@@ -0,0 +1,30 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssml
+
+import "strings"
+
+// Supports reports whether voice (e.g. "en-US_AllisonVoice") supports the SSML element. Elements not
+// listed below are supported by all voices.
+func Supports(voice string, element string) bool {
+	switch element {
+	case "express-as":
+		return voice == "en-US_AllisonVoice"
+	case "voice-transformation":
+		return strings.HasPrefix(voice, "en-US_")
+	}
+	return true
+}
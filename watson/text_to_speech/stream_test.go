@@ -0,0 +1,54 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+)
+
+func TestSynthesizeTo(t *testing.T) {
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/synthesize" || r.URL.Query().Get("voice") != "en-US_AllisonVoice" || r.Header.Get("Accept") != "audio/wav" {
+			t.Errorf("unexpected request %s\n", r.URL)
+		}
+		if r.URL.Query().Get("customization_id") == "bad" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "error": "Customization bad not found"}`))
+			return
+		}
+		// audio is flushed as it is synthesized
+		w.Write([]byte("RIFF"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("...."))
+	})
+	defer closeServer()
+
+	var buf bytes.Buffer
+	n, err := c.SynthesizeTo(context.Background(), &buf, "hello", "en-US_AllisonVoice", "audio/wav", "")
+	if err != nil || n != 8 || buf.String() != "RIFF...." {
+		t.Errorf("SynthesizeTo() returned %d, %#v and wrote %q\n", n, err, buf.String())
+	}
+
+	_, err = c.SynthesizeReader(context.Background(), "hello", "en-US_AllisonVoice", "audio/wav", "bad")
+	if werr, ok := err.(*watson.WatsonError); !ok || werr.Code != 404 {
+		t.Errorf("SynthesizeReader() with an error reply returned %#v\n", err)
+	}
+}
@@ -18,16 +18,22 @@ package text_to_speech
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 
 	"github.com/liviosoares/go-watson-sdk/watson"
+	"github.com/liviosoares/go-watson-sdk/watson/authorization"
 )
 
 type Client struct {
 	version      string
 	watsonClient *watson.Client
+	// tokens caches the token used to open websocket sessions
+	tokens *authorization.TokenCache
 }
 
 const defaultMajorVersion = "v1"
@@ -42,14 +48,21 @@ func NewClient(cfg watson.Config) (Client, error) {
 	if len(cfg.Credentials.Url) == 0 {
 		cfg.Credentials.Url = defaultUrl
 	}
-	client, err := watson.NewClient(cfg.Credentials)
+	client, err := watson.NewClientFromConfig(cfg)
 	if err != nil {
 		return Client{}, err
 	}
 	tts.watsonClient = client
+	tts.tokens = authorization.NewTokenCache(client, 0)
 	return tts, nil
 }
 
+// WithHeaders returns a copy of the client sending header with every request; see watson.Client.WithHeaders.
+func (c Client) WithHeaders(header http.Header) Client {
+	c.watsonClient = c.watsonClient.WithHeaders(header)
+	return c
+}
+
 type VoiceList struct {
 	Voices []Voice `json:"voices"`
 }
@@ -86,6 +99,8 @@ type Customization struct {
 	LastModified int `json:"last_modified"`
 	// Description of the custom voice model.
 	Description string `json:"description"`
+	// Custom words of the custom voice model; returned only by GetCustomization().
+	Words []Word `json:"words,omitempty"`
 }
 
 // Calls 'GET /v1/voices' to retrieve all voices available for speech synthesis
@@ -114,8 +129,21 @@ func (c Client) GetVoice(voice_id string, customization_id string) (Voice, error
 	return voice, err
 }
 
+// Calls 'POST /v1/synthesize' to synthesize text, returning the whole audio.
 // Valid accept values are: "audio/ogg; codecs=opus", "audio/wav", "audio/flac"
 func (c Client) Synthesize(text string, voice string, accept string, customization_id string) ([]byte, error) {
+	audio, err := c.SynthesizeReader(context.Background(), text, voice, accept, customization_id)
+	if err != nil {
+		return nil, err
+	}
+	defer audio.Close()
+	return ioutil.ReadAll(audio)
+}
+
+// SynthesizeReader is like Synthesize, but returns the audio as it is received from the service, so that
+// it can be played or forwarded before synthesis completes. The caller must close the returned reader;
+// the request is canceled when ctx is done.
+func (c Client) SynthesizeReader(ctx context.Context, text string, voice string, accept string, customization_id string) (io.ReadCloser, error) {
 	t := struct {
 		Text string `json:"text"`
 	}{Text: text}
@@ -133,7 +161,22 @@ func (c Client) Synthesize(text string, voice string, accept string, customizati
 	headers := make(http.Header)
 	headers.Set("Content-Type", "application/json")
 	headers.Set("Accept", accept)
-	return c.watsonClient.MakeRequest("POST", c.version+"/synthesize?"+q.Encode(), bytes.NewReader(text_json), headers)
+	resp, err := c.watsonClient.DoRequest(ctx, "POST", c.version+"/synthesize?"+q.Encode(), bytes.NewReader(text_json), headers)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// SynthesizeTo is like Synthesize, but writes the audio to w as it is received from the service, for example
+// to an http.ResponseWriter or a file. It returns the number of bytes written.
+func (c Client) SynthesizeTo(ctx context.Context, w io.Writer, text string, voice string, accept string, customization_id string) (int64, error) {
+	audio, err := c.SynthesizeReader(ctx, text, voice, accept, customization_id)
+	if err != nil {
+		return 0, err
+	}
+	defer audio.Close()
+	return io.Copy(w, audio)
 }
 
 // Calls 'GET /v1/pronunciation' to get the pronunciation for a word
@@ -0,0 +1,203 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoVoice is returned when no voice matches the requested language
+var ErrNoVoice = errors.New("no voice for the requested language")
+
+// VoiceFilter selects voices in VoiceList.Filter(); empty fields match any voice
+type VoiceFilter struct {
+	// Language tag (for example, "en-US"), or a language without region (for example, "en") to match all its regions
+	Language string
+	// "male" or "female"
+	Gender string
+	// If true, only voices which can be customized match
+	Customizable bool
+}
+
+// Filter returns the voices of l matching f, sorted by name
+func (l VoiceList) Filter(f VoiceFilter) []Voice {
+	language, region := parseLanguageTag(f.Language)
+	var voices []Voice
+	for _, v := range l.Voices {
+		vl, vr := parseLanguageTag(v.Language)
+		switch {
+		case len(language) > 0 && vl != language:
+		case len(region) > 0 && vr != region:
+		case len(f.Gender) > 0 && !strings.EqualFold(v.Gender, f.Gender):
+		case f.Customizable && !v.Customizable:
+		default:
+			voices = append(voices, v)
+		}
+	}
+	sort.Slice(voices, func(i, j int) bool { return voices[i].Name < voices[j].Name })
+	return voices
+}
+
+// Select returns the voice of l best matching the BCP-47 language tag (for example, "en-AU", "pt-BR" or "fr"),
+// preferably of gender (if not empty). Voices of the same language and region are preferred, then voices of
+// the language in another region. If no voice speaks the language, the fallback tags are tried in order;
+// ErrNoVoice is returned if none matches either. Among equally good matches, voices of gender are preferred,
+// then the first voice by name.
+func (l VoiceList) Select(tag string, gender string, fallbacks ...string) (Voice, error) {
+	for _, t := range append([]string{tag}, fallbacks...) {
+		language, region := parseLanguageTag(t)
+		if len(language) == 0 {
+			continue
+		}
+		best, bestScore := Voice{}, 0
+		for _, v := range l.Filter(VoiceFilter{Language: language}) {
+			score := 2
+			if _, vr := parseLanguageTag(v.Language); len(region) > 0 && vr == region {
+				score = 4
+			}
+			if len(gender) > 0 && strings.EqualFold(v.Gender, gender) {
+				score++
+			}
+			if score > bestScore {
+				best, bestScore = v, score
+			}
+		}
+		if bestScore > 0 {
+			return best, nil
+		}
+	}
+	return Voice{}, ErrNoVoice
+}
+
+// parseLanguageTag returns the lower case language and upper case region of a BCP-47 tag (for example,
+// "zh-Hans-CN" or "en_us"), ignoring scripts, variants and extensions
+func parseLanguageTag(tag string) (string, string) {
+	subtags := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 {
+		return "", ""
+	}
+	language := strings.ToLower(subtags[0])
+	for _, s := range subtags[1:] {
+		switch {
+		case len(s) == 1:
+			// extensions and private use subtags follow
+			return language, ""
+		case len(s) == 2 || (len(s) == 3 && s[0] >= '0' && s[0] <= '9'):
+			return language, strings.ToUpper(s)
+		}
+	}
+	return language, ""
+}
+
+// VoiceCatalog caches the voices of the service, so that voices can be selected without calling ListVoices()
+// every time. The voices are requested again once older than the TTL of the catalog. A VoiceCatalog is safe for
+// use by multiple goroutines.
+type VoiceCatalog struct {
+	client Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	voices  VoiceList
+	fetched bool
+	expires time.Time
+	// fetching is closed once the voices being requested by another goroutine have been received
+	fetching chan struct{}
+}
+
+// NewVoiceCatalog creates a catalog of the voices of the service. A ttl of zero defaults to one hour.
+func (c Client) NewVoiceCatalog(ttl time.Duration) *VoiceCatalog {
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &VoiceCatalog{client: c, ttl: ttl}
+}
+
+// Voices returns a copy of the cached voices, requesting them if they have expired. The voices are requested
+// without holding up other callers, which are returned the expired voices meanwhile. If the request fails but
+// voices were cached before, the cached voices are returned, and the request is retried after a minute (or the
+// TTL, if shorter).
+func (c *VoiceCatalog) Voices() (VoiceList, error) {
+	c.mu.Lock()
+	for !c.fetched && c.fetching != nil {
+		// nothing to return until the first request completes
+		fetching := c.fetching
+		c.mu.Unlock()
+		<-fetching
+		c.mu.Lock()
+	}
+	if c.fetched && (c.fetching != nil || time.Now().Before(c.expires)) {
+		voices := c.copyVoices()
+		c.mu.Unlock()
+		return voices, nil
+	}
+	fetching := make(chan struct{})
+	c.fetching = fetching
+	c.mu.Unlock()
+
+	voices, err := c.client.ListVoices()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching = nil
+	close(fetching)
+	if err != nil {
+		if !c.fetched {
+			return VoiceList{}, err
+		}
+		retry := time.Minute
+		if c.ttl < retry {
+			retry = c.ttl
+		}
+		c.expires = time.Now().Add(retry)
+		return c.copyVoices(), nil
+	}
+	c.voices, c.fetched, c.expires = voices, true, time.Now().Add(c.ttl)
+	return c.copyVoices(), nil
+}
+
+// copyVoices returns a copy of the cached voices, which callers may modify; c.mu must be held
+func (c *VoiceCatalog) copyVoices() VoiceList {
+	return VoiceList{Voices: append([]Voice(nil), c.voices.Voices...)}
+}
+
+// Refresh requests the voices again at the next call to the catalog
+func (c *VoiceCatalog) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires = time.Time{}
+}
+
+// Filter returns the cached voices matching f; see VoiceList.Filter()
+func (c *VoiceCatalog) Filter(f VoiceFilter) ([]Voice, error) {
+	voices, err := c.Voices()
+	if err != nil {
+		return nil, err
+	}
+	return voices.Filter(f), nil
+}
+
+// Select returns the cached voice best matching tag; see VoiceList.Select()
+func (c *VoiceCatalog) Select(tag string, gender string, fallbacks ...string) (Voice, error) {
+	voices, err := c.Voices()
+	if err != nil {
+		return Voice{}, err
+	}
+	return voices.Select(tag, gender, fallbacks...)
+}
@@ -0,0 +1,157 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testVoices = VoiceList{Voices: []Voice{
+	{Name: "en-US_MichaelVoice", Language: "en-US", Gender: "male", Customizable: true},
+	{Name: "en-US_AllisonVoice", Language: "en-US", Gender: "female", Customizable: true},
+	{Name: "en-GB_KateVoice", Language: "en-GB", Gender: "female", Customizable: true},
+	{Name: "es-LA_SofiaVoice", Language: "es-LA", Gender: "female"},
+	{Name: "es-ES_EnriqueVoice", Language: "es-ES", Gender: "male", Customizable: true},
+	{Name: "pt-BR_IsabelaVoice", Language: "pt-BR", Gender: "female"},
+}}
+
+func voiceNames(voices []Voice) []string {
+	var names []string
+	for _, v := range voices {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestFilterVoices(t *testing.T) {
+	tests := []struct {
+		filter   VoiceFilter
+		expected string
+	}{
+		{VoiceFilter{Language: "en"}, "[en-GB_KateVoice en-US_AllisonVoice en-US_MichaelVoice]"},
+		{VoiceFilter{Language: "en-us", Gender: "Female"}, "[en-US_AllisonVoice]"},
+		{VoiceFilter{Language: "es", Customizable: true}, "[es-ES_EnriqueVoice]"},
+		{VoiceFilter{Gender: "male"}, "[en-US_MichaelVoice es-ES_EnriqueVoice]"},
+		{VoiceFilter{Language: "fr"}, "[]"},
+	}
+	for _, test := range tests {
+		if names := voiceNames(testVoices.Filter(test.filter)); fmt.Sprint(names) != test.expected {
+			t.Errorf("Filter(%#v) returned %v\n", test.filter, names)
+		}
+	}
+}
+
+func TestSelectVoice(t *testing.T) {
+	tests := []struct {
+		tag, gender string
+		fallbacks   []string
+		expected    string
+	}{
+		{"en-GB", "", nil, "en-GB_KateVoice"},
+		{"en-US", "", nil, "en-US_AllisonVoice"},
+		{"en-US", "male", nil, "en-US_MichaelVoice"},
+		{"en-AU", "", nil, "en-GB_KateVoice"},
+		{"en-AU", "male", nil, "en-US_MichaelVoice"},
+		{"en-Latn-GB-x-test", "", nil, "en-GB_KateVoice"},
+		{"es-419", "", nil, "es-ES_EnriqueVoice"},
+		{"pt_br", "male", nil, "pt-BR_IsabelaVoice"},
+		{"pt-PT", "", nil, "pt-BR_IsabelaVoice"},
+		{"ca-ES", "", []string{"fr-FR", "es-ES"}, "es-ES_EnriqueVoice"},
+	}
+	for _, test := range tests {
+		v, err := testVoices.Select(test.tag, test.gender, test.fallbacks...)
+		if err != nil || v.Name != test.expected {
+			t.Errorf("Select(%#v, %#v) returned %#v, %#v\n", test.tag, test.gender, v.Name, err)
+		}
+	}
+	if _, err := testVoices.Select("ja-JP", "", "de"); err != ErrNoVoice {
+		t.Errorf("Select() of a missing language returned %#v\n", err)
+	}
+}
+
+func TestVoiceCatalog(t *testing.T) {
+	calls, fail := 0, false
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"voices": [{"name": "en-US_AllisonVoice", "language": "en-US", "gender": "female"}]}`))
+	})
+	defer closeServer()
+
+	catalog := c.NewVoiceCatalog(time.Hour)
+	for i := 0; i < 3; i++ {
+		v, err := catalog.Select("en", "")
+		if err != nil || v.Name != "en-US_AllisonVoice" {
+			t.Errorf("Select() returned %#v, %#v\n", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("VoiceCatalog requested the voices %d times\n", calls)
+	}
+	// cached voices are still returned if the service fails
+	catalog.Refresh()
+	fail = true
+	voices, err := catalog.Filter(VoiceFilter{})
+	if err != nil || len(voices) != 1 || calls != 2 {
+		t.Errorf("Filter() after a failed refresh returned %#v, %#v after %d calls\n", voices, err, calls)
+	}
+	if _, err = c.NewVoiceCatalog(0).Voices(); err == nil {
+		t.Errorf("Voices() of a failing service succeeded\n")
+	}
+}
+
+func TestVoiceCatalogRefresh(t *testing.T) {
+	requested, reply := make(chan bool), make(chan string)
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requested <- true
+		w.Write([]byte(`{"voices": [{"name": "` + <-reply + `", "language": "en-US"}]}`))
+	})
+	defer closeServer()
+	catalog := c.NewVoiceCatalog(time.Hour)
+	go func() {
+		<-requested
+		reply <- "en-US_AllisonVoice"
+	}()
+	voices, err := catalog.Voices()
+	if err != nil || len(voices.Voices) != 1 {
+		t.Fatalf("Voices() returned %#v, %#v\n", voices, err)
+	}
+	// callers get their own copy of the voices
+	voices.Voices[0].Name = "changed"
+
+	// while the voices are requested again, the expired voices are returned without waiting for the service
+	catalog.Refresh()
+	refreshed := make(chan VoiceList)
+	go func() {
+		voices, _ := catalog.Voices()
+		refreshed <- voices
+	}()
+	<-requested
+	voices, err = catalog.Voices()
+	if err != nil || voices.Voices[0].Name != "en-US_AllisonVoice" {
+		t.Errorf("Voices() during a refresh returned %#v, %#v\n", voices, err)
+	}
+	reply <- "en-US_LisaVoice"
+	if voices := <-refreshed; voices.Voices[0].Name != "en-US_LisaVoice" {
+		t.Errorf("Voices() after a refresh returned %#v\n", voices)
+	}
+}
@@ -0,0 +1,299 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// SynthesisEvent is received from a websocket synthesis session. Each event carries either a chunk of audio,
+// timing information, or warnings and errors of the service.
+type SynthesisEvent struct {
+	// Chunk of the synthesized audio
+	Audio []byte
+	// Content type of the audio; set in the event preceding the first chunk of audio
+	ContentType string
+	// Timings of the words of the text, in the order they are spoken
+	Words []WordTiming
+	// Times at which the marks of SSML text are reached
+	Marks []Mark
+	// Warnings about invalid parameters of the request; the synthesis succeeds despite them
+	Warnings string
+	// Set in the final event of a session ended by an error of the service
+	Error string
+}
+
+// WordTiming is the time at which a word of the text is spoken, in seconds from the start of the audio
+type WordTiming struct {
+	Word  string
+	Start float64
+	End   float64
+}
+
+// Mark is the time, in seconds from the start of the audio, at which a <mark name="..."/> element of SSML text
+// is reached
+type Mark struct {
+	Name string
+	Time float64
+}
+
+// ErrSynthesisAborted is reported by SynthesisSession.Err after a session was ended with Abort.
+var ErrSynthesisAborted = errors.New("synthesis session aborted")
+
+// SynthesisSession is a synthesis request over the text-to-speech websocket interface. Audio and timing
+// events are received from Events(), until the whole text has been synthesized.
+type SynthesisSession struct {
+	ws      *websocket.Conn
+	events  chan SynthesisEvent
+	closing chan struct{}
+	done    chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// SynthesizeStream synthesizes text (plain or SSML) over the websocket interface, returning a channel of events
+// carrying the audio as it is synthesized, along with the timing of every word and of every SSML mark. The channel
+// is closed once synthesis completes; an error of the service is delivered in the final event. Valid accept
+// values are the same as for Synthesize(); if empty, "audio/ogg;codecs=opus" is used. Synthesis is aborted, and
+// the channel closed, when ctx is done; callers which stop reading the channel early must cancel ctx.
+//
+// SynthesizeStream is a shorthand for NewSynthesisSession; use the latter to observe errors.
+func (c Client) SynthesizeStream(ctx context.Context, text string, voice string, accept string, customization_id string) (<-chan SynthesisEvent, error) {
+	s, err := c.NewSynthesisSession(text, voice, accept, customization_id)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Abort()
+		case <-s.Done():
+		}
+	}()
+	return s.Events(), nil
+}
+
+// NewSynthesisSession opens a websocket to the text-to-speech API, and requests the synthesis of text with
+// voice (the service default if empty), and the custom voice model customization_id (if not empty).
+// See SynthesizeStream().
+func (c Client) NewSynthesisSession(text string, voice string, accept string, customization_id string) (*SynthesisSession, error) {
+	// in dry-run mode, no token is requested; it is masked in the rendered command anyway
+	var token string
+	if c.watsonClient.DryRun == nil {
+		var err error
+		token, err = c.tokens.Token()
+		if err != nil {
+			return nil, errors.New("failed to acquire auth token: " + err.Error())
+		}
+	}
+	u, err := url.Parse(c.watsonClient.Creds.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "ws"
+	} else {
+		u.Scheme = "wss"
+	}
+	q := url.Values{}
+	q.Set("watson-token", token)
+	if len(voice) > 0 {
+		q.Set("voice", voice)
+	}
+	if len(customization_id) > 0 {
+		q.Set("customization_id", customization_id)
+	}
+	u.RawQuery = q.Encode()
+	u.Path += c.version + "/synthesize"
+	if c.watsonClient.DryRun != nil {
+		return nil, c.watsonClient.DryRunWebsocket(u)
+	}
+
+	origin, err := url.Parse(c.watsonClient.Creds.Url)
+	if err != nil {
+		return nil, err
+	}
+	config := &websocket.Config{
+		Location: u,
+		Origin:   origin,
+		Version:  websocket.ProtocolVersionHybi13,
+		Header:   c.watsonClient.Headers,
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		// the token may have been revoked or expired; request a new one next time
+		c.tokens.Invalidate()
+		return nil, errors.New("error dialing websocket: " + err.Error())
+	}
+
+	if len(accept) == 0 {
+		accept = "audio/ogg;codecs=opus"
+	}
+	err = websocket.JSON.Send(ws, map[string]interface{}{
+		"text":    text,
+		"accept":  accept,
+		"timings": []string{"words"},
+	})
+	if err != nil {
+		ws.Close()
+		return nil, err
+	}
+	s := &SynthesisSession{
+		ws:      ws,
+		events:  make(chan SynthesisEvent, 100),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.readReplies()
+	return s, nil
+}
+
+// Events returns the channel on which synthesis events are delivered. It is closed when the session ends.
+func (s *SynthesisSession) Events() <-chan SynthesisEvent {
+	return s.events
+}
+
+// Done returns a channel which is closed once the session has ended and Events has been closed.
+func (s *SynthesisSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error which ended the session, if any. It should be called once Done is closed.
+func (s *SynthesisSession) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Abort immediately ends the session, closing the connection to the service. Pending events are discarded.
+func (s *SynthesisSession) Abort() {
+	s.finish(ErrSynthesisAborted)
+}
+
+// finish ends the session, recording err as the cause unless the session ended already
+func (s *SynthesisSession) finish(err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.err = err
+	s.mu.Unlock()
+	close(s.closing)
+	s.ws.Close()
+}
+
+// frame is a websocket message along with its type
+type frame struct {
+	payloadType byte
+	data        []byte
+}
+
+var frameCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		f := v.(*frame)
+		f.payloadType = payloadType
+		f.data = data
+		return nil
+	},
+}
+
+// synthesisReply is a text message of the service
+type synthesisReply struct {
+	BinaryStreams []struct {
+		ContentType string `json:"content_type"`
+	} `json:"binary_streams"`
+	Words    [][]interface{} `json:"words"`
+	Marks    [][]interface{} `json:"marks"`
+	Warnings string          `json:"warnings"`
+	Error    string          `json:"error"`
+}
+
+func (s *SynthesisSession) readReplies() {
+	defer close(s.done)
+	defer close(s.events)
+	for {
+		var f frame
+		err := frameCodec.Receive(s.ws, &f)
+		if err != nil {
+			// the service closes the connection once the whole text has been synthesized
+			if err == io.EOF {
+				err = nil
+			}
+			s.finish(err)
+			return
+		}
+		if f.payloadType == websocket.BinaryFrame {
+			if !s.deliver(SynthesisEvent{Audio: f.data}) {
+				return
+			}
+			continue
+		}
+		var reply synthesisReply
+		if json.Unmarshal(f.data, &reply) != nil {
+			continue
+		}
+		event := SynthesisEvent{Warnings: reply.Warnings, Error: reply.Error}
+		if len(reply.BinaryStreams) > 0 {
+			event.ContentType = reply.BinaryStreams[0].ContentType
+		}
+		for _, w := range reply.Words {
+			if len(w) < 3 {
+				continue
+			}
+			word, _ := w[0].(string)
+			start, _ := w[1].(float64)
+			end, _ := w[2].(float64)
+			event.Words = append(event.Words, WordTiming{Word: word, Start: start, End: end})
+		}
+		for _, m := range reply.Marks {
+			if len(m) < 2 {
+				continue
+			}
+			name, _ := m[0].(string)
+			at, _ := m[1].(float64)
+			event.Marks = append(event.Marks, Mark{Name: name, Time: at})
+		}
+		if len(event.Error) > 0 {
+			s.deliver(event)
+			s.finish(errors.New(event.Error))
+			return
+		}
+		if !s.deliver(event) {
+			return
+		}
+	}
+}
+
+// deliver pushes event to the events channel, unless the session is closed first
+func (s *SynthesisSession) deliver(event SynthesisEvent) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.closing:
+		return false
+	}
+}
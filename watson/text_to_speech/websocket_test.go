@@ -0,0 +1,121 @@
+//
+// Copyright (C) IBM Corporation 2016, Livio Soares <lsoares@us.ibm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_to_speech
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/liviosoares/go-watson-sdk/watson"
+	"golang.org/x/net/websocket"
+)
+
+// fakeSynthesizer replies to a synthesis request like the service, with the text "Hello <mark/> world"
+func fakeSynthesizer(ws *websocket.Conn) {
+	defer ws.Close()
+	var request map[string]interface{}
+	if websocket.JSON.Receive(ws, &request) != nil {
+		return
+	}
+	if request["text"] == "hang" {
+		// synthesize nothing, until the client goes away
+		websocket.Message.Send(ws, `{"binary_streams": [{"content_type": "audio/wav"}]}`)
+		websocket.JSON.Receive(ws, &request)
+		return
+	}
+	if request["text"] == "fail" {
+		websocket.Message.Send(ws, `{"error": "Synthesis failed", "code": 500}`)
+		return
+	}
+	websocket.Message.Send(ws, `{"binary_streams": [{"content_type": "`+request["accept"].(string)+`"}]}`)
+	websocket.Message.Send(ws, `{"words": [["Hello", 0.0, 0.4]]}`)
+	websocket.Message.Send(ws, []byte("aaaa"))
+	websocket.Message.Send(ws, `{"marks": [["here", 0.45]]}`)
+	websocket.Message.Send(ws, `{"words": [["world", 0.45, 0.9]]}`)
+	websocket.Message.Send(ws, []byte("bbbb"))
+}
+
+func TestSynthesizeStream(t *testing.T) {
+	c, closeServer := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/authorization/"):
+			w.Write([]byte("token"))
+		case r.URL.Path == "/v1/synthesize":
+			if r.URL.Query().Get("voice") != "en-US_AllisonVoice" || r.URL.Query().Get("watson-token") != "token" ||
+				r.Header.Get(watson.LearningOptOutHeader) != "true" {
+				t.Errorf("unexpected request %s\n", r.URL)
+			}
+			websocket.Handler(fakeSynthesizer).ServeHTTP(w, r)
+		default:
+			t.Errorf("unexpected request %s\n", r.URL)
+		}
+	})
+	defer closeServer()
+	c = c.WithHeaders(http.Header{watson.LearningOptOutHeader: {"true"}})
+
+	events, err := c.SynthesizeStream(context.Background(), `<speak>Hello <mark name="here"/> world</speak>`, "en-US_AllisonVoice", "audio/wav", "")
+	if err != nil {
+		t.Errorf("SynthesizeStream() failed %#v\n", err)
+		return
+	}
+	var got []SynthesisEvent
+	for e := range events {
+		got = append(got, e)
+	}
+	expected := []SynthesisEvent{
+		{ContentType: "audio/wav"},
+		{Words: []WordTiming{{Word: "Hello", Start: 0, End: 0.4}}},
+		{Audio: []byte("aaaa")},
+		{Marks: []Mark{{Name: "here", Time: 0.45}}},
+		{Words: []WordTiming{{Word: "world", Start: 0.45, End: 0.9}}},
+		{Audio: []byte("bbbb")},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("SynthesizeStream() returned %#v\n", got)
+	}
+
+	// the session ends once ctx is done, although the service has not finished
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err = c.SynthesizeStream(ctx, "hang", "en-US_AllisonVoice", "audio/wav", "")
+	if err != nil {
+		t.Errorf("SynthesizeStream() failed %#v\n", err)
+		return
+	}
+	if e := <-events; e.ContentType != "audio/wav" {
+		t.Errorf("SynthesizeStream() returned %#v\n", e)
+	}
+	cancel()
+	for range events {
+	}
+
+	s, err := c.NewSynthesisSession("fail", "en-US_AllisonVoice", "", "")
+	if err != nil {
+		t.Errorf("NewSynthesisSession() failed %#v\n", err)
+		return
+	}
+	got = nil
+	for e := range s.Events() {
+		got = append(got, e)
+	}
+	<-s.Done()
+	if len(got) != 1 || got[0].Error != "Synthesis failed" || s.Err() == nil {
+		t.Errorf("NewSynthesisSession() returned %#v, %#v\n", got, s.Err())
+	}
+}